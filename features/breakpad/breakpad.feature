@@ -16,3 +16,16 @@ Feature: Breakpad Integration Tests
         | code_file         | /features/breakpad/fixtures/breakpad-symbols.sym  |
         | debug_identifier  | 1234567890ABCDEF1234567890ABCDEF                  |
         | product           | test-product                                      |
+
+    Scenario: Upload a single breakpad .sym using details from the MODULE record
+        When I run bugsnag-cli with upload breakpad features/breakpad/fixtures/breakpad-symbols.sym --upload-api-root-url=http://localhost:$MAZE_RUNNER_PORT --api-key=1234567890ABCDEF1234567890ABCDEF
+        And I wait to receive 1 sourcemaps
+        Then the sourcemaps are valid for the API
+        Then the sourcemaps Content-Type header is valid multipart form-data
+        And the sourcemap "api_key" query parameter equals "1234567890ABCDEF1234567890ABCDEF"
+        Then the sourcemap payload fields should be:
+        | os                | Linux                             |
+        | cpu               | x86_64                            |
+        | debug_file        | chrome                            |
+        | code_file         | chrome                            |
+        | debug_identifier  | CB77944DB22F6H3S00000000000000000 |
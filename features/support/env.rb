@@ -1,6 +1,8 @@
 BeforeAll do
   $api_key = '1234567890ABCDEF1234567890ABCDEF'
   ENV['MAZE_RUNNER_PORT'] ||= '9339'
+  # Scenarios upload the same fixtures repeatedly, so don't skip previously uploaded files
+  ENV['BUGSNAG_NO_CACHE'] = 'true'
 end
 
 def run_output
@@ -1,14 +1,26 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 
+	"github.com/bugsnag/bugsnag-cli/pkg/breakpad"
 	"github.com/bugsnag/bugsnag-cli/pkg/build"
+	"github.com/bugsnag/bugsnag-cli/pkg/cache"
+	"github.com/bugsnag/bugsnag-cli/pkg/config"
+	"github.com/bugsnag/bugsnag-cli/pkg/debugid"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/mockserver"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/bugsnag/bugsnag-cli/pkg/symbolicate"
 	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/bugsnag/bugsnag-cli/pkg/validate"
 )
 
 var package_version = "3.10.3"
@@ -22,25 +34,77 @@ func main() {
 		os.Args = append(os.Args, "--help")
 	}
 
+	// Options not passed as flags are read from the environment or the project configuration file
+	workingDir, _ := os.Getwd()
+	configResolver, err := config.Load(workingDir)
+	if err != nil {
+		log.NewLoggerWrapper("", log.OutputText).Fatal(err.Error())
+	}
+
 	kongCtx := kong.Parse(&commands,
 		kong.ConfigureHelp(kong.HelpOptions{
 			Compact: true,
 		}),
 		kong.Vars{
 			"version": package_version,
-		})
+		},
+		kong.Resolvers(configResolver))
 
 	if commands.Verbose {
 		commands.LogLevel = "debug"
 	}
 
-	logger := log.NewLoggerWrapper(commands.LogLevel)
+	// Mask the API key, and other secrets, in everything that is logged
+	log.SetShowSecrets(commands.ShowSecrets)
+	log.AddSecret(commands.ApiKey)
+
+	logger := log.NewLoggerWrapper(commands.LogLevel, commands.Output)
+	logger.SetCommand(kongCtx.Command())
+
+	// Commands that write their results to stdout log to stderr so that the results can be piped
+	if strings.HasPrefix(kongCtx.Command(), "symbolicate ") || (strings.HasPrefix(kongCtx.Command(), "compose-sourcemaps ") && commands.ComposeSourcemaps.OutputFile == "") {
+		logger.SetOutput(os.Stderr)
+	}
 
 	if commands.DryRun {
 		logger.Info("Performing dry run - no data will be sent to BugSnag")
+		configResolver.LogResolvedOptions(kongCtx, logger)
+	}
+
+	// --timeout is replaced by a timeout for each stage of a request
+	if commands.Upload.Timeout > 0 {
+		timeout := time.Duration(commands.Upload.Timeout) * time.Second
+		logger.Warn(fmt.Sprintf("`--timeout` is deprecated and no longer limits the total time of a request, please use `--connect-timeout`, `--tls-timeout`, `--response-timeout` and `--idle-timeout` instead. Using %s for each of them", timeout))
+		commands.Upload.ConnectTimeout = timeout
+		commands.Upload.TlsTimeout = timeout
+		commands.Upload.ResponseTimeout = timeout
+		commands.Upload.IdleTimeout = timeout
+	}
+
+	// Check the proxy and TLS options before any files are processed
+	if _, err := server.NewTransport(commands); err != nil {
+		logger.Fatal(err.Error())
+	}
+	if commands.InsecureSkipVerify {
+		logger.Warn("TLS certificate verification is disabled by --insecure-skip-verify, only use this for testing")
 	}
 
-	switch kongCtx.Command() {
+	server.ConfigureProgress(!commands.DryRun && !commands.Upload.NoProgress, commands.Upload.ProgressInterval)
+
+	// Package commands run the upload commands, writing their requests to a symbol bundle
+	command := kongCtx.Command()
+	var packager *server.Packager
+	if strings.HasPrefix(command, "package ") {
+		packager, err = upload.ProcessPackage(&commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		command = "upload " + strings.TrimPrefix(command, "package ")
+	}
+
+	switch command {
 
 	case "upload all <path>":
 
@@ -198,6 +262,13 @@ func main() {
 			logger.Fatal(err.Error())
 		}
 
+	case "upload bundle <archive>":
+		err := upload.ProcessUploadBundle(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
 	case "create-build", "create-build <path>":
 		// Create Build Info
 		CreateBuildOptions, err := build.GatherBuildInfo(commands)
@@ -221,6 +292,27 @@ func main() {
 
 		logger.Info("Build created")
 
+	case "compose-sourcemaps <source-maps>":
+		err := sourcemap.ProcessComposeSourcemaps(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "inject-debug-ids", "inject-debug-ids <path>":
+		err := debugid.ProcessInjectDebugIds(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "create-breakpad-symbols <path>":
+		err := breakpad.ProcessCreateBreakpadSymbols(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
 	case "create-android-build-id", "create-android-build-id <path>":
 		err := build.PrintAndroidBuildId(commands.CreateAndroidBuildId.Path)
 
@@ -228,7 +320,59 @@ func main() {
 			logger.Fatal(err.Error())
 		}
 
+	case "cache prune":
+		err := cache.ProcessCachePrune(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "validate js", "validate js <path>":
+		err := validate.ProcessValidateJs(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "symbolicate android-proguard", "symbolicate android-proguard <path>":
+		err := symbolicate.ProcessSymbolicateAndroidProguard(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "symbolicate js", "symbolicate js <path>":
+		err := symbolicate.ProcessSymbolicateJs(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "symbolicate native", "symbolicate native <path>":
+		err := symbolicate.ProcessSymbolicateNative(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+	case "mock-server":
+		err := mockserver.ProcessMockServer(commands, logger)
+
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
 	default:
 		println(kongCtx.Command())
 	}
+
+	if packager != nil {
+		requests := packager.Requests()
+		if err := packager.Close(); err != nil {
+			logger.Fatal(err.Error())
+		}
+		logger.Info(fmt.Sprintf("Wrote %d upload request(s) to the symbol bundle %s", requests, commands.Package.OutputFile))
+	}
+
+	logger.WriteSummary("")
 }
@@ -76,6 +76,7 @@ func MergeUploadOptionsFromAabManifest(
 
 		if aabUploadOptions["apiKey"] == "" && manifestData["apiKey"] != "" {
 			aabUploadOptions["apiKey"] = manifestData["apiKey"]
+			log.AddSecret(manifestData["apiKey"])
 			logger.Debug(fmt.Sprintf("Using %s as API key from AndroidManifest.xml", manifestData["apiKey"]))
 		}
 
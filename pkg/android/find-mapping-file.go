@@ -0,0 +1,45 @@
+package android
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// FindMappingFile finds the Proguard/R8 mapping file for a variant in an Android project,
+// which is expected at <path>/app/build/outputs/mapping/<variant>/mapping.txt.
+//
+// Parameters:
+//   - path: The root directory of the Android project.
+//   - variant: The build variant, or an empty string to use the only variant that has a mapping file.
+//
+// Returns:
+//   - string: The path to the mapping file.
+//   - string: The variant of the mapping file.
+//   - error: non-nil if the mapping file cannot be found.
+func FindMappingFile(path string, variant string) (string, string, error) {
+	mappingPath := filepath.Join(path, "app", "build", "outputs", "mapping")
+
+	if !utils.FileExists(mappingPath) {
+		return "", "", fmt.Errorf("unable to find the mapping directory in %s", path)
+	}
+
+	// Determine build variant if not specified
+	if variant == "" {
+		var err error
+		variant, err = GetVariantDirectory(mappingPath)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// Compose full path to mapping.txt for the variant
+	mappingFile := filepath.Join(mappingPath, variant, "mapping.txt")
+
+	if !utils.FileExists(mappingFile) {
+		return "", "", fmt.Errorf("unable to find mapping file in the specified project directory")
+	}
+
+	return mappingFile, variant, nil
+}
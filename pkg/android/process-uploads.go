@@ -2,7 +2,9 @@ package android
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
@@ -50,7 +52,8 @@ func buildUploadOptions(appID, versionCode, versionName, projectRoot, fileName s
 // to the Bugsnag /ndk-symbol endpoint.
 //
 // Each file is uploaded with associated metadata including app ID, version code/name,
-// and shared object name. If no symbol files are present, the upload is skipped.
+// and shared object name, using the upload worker pool. Files are uploaded in path order.
+// If no symbol files are present, the upload is skipped.
 //
 // Parameters:
 //   - symbolFiles: Map of original file path → generated symbol file path
@@ -63,7 +66,7 @@ func buildUploadOptions(appID, versionCode, versionName, projectRoot, fileName s
 //   - logger: Logger instance for debug/info/error output
 //
 // Returns:
-//   - error: Non-nil if any file fails to upload
+//   - error: Non-nil if any file fails to upload; failures are aggregated across all files
 func UploadAndroidNdk(
 	symbolFiles map[string]string,
 	apiKey string,
@@ -80,26 +83,34 @@ func UploadAndroidNdk(
 		return nil
 	}
 
-	for originalFile, symbolPath := range symbolFiles {
-		fileField := map[string]server.FileField{
-			"soFile": server.LocalFile(symbolPath),
-		}
+	var jobs []server.UploadJob
+	for _, originalFile := range slices.Sorted(maps.Keys(symbolFiles)) {
+		symbolPath := symbolFiles[originalFile]
+		jobs = append(jobs, server.UploadJob{
+			Name: originalFile,
+			Run: func(logger log.Logger) error {
+				fileField := map[string]server.FileField{
+					"soFile": server.LocalFile(symbolPath),
+				}
 
-		params := buildUploadOptions(appID, versionCode, versionName, projectRoot, originalFile, overwrite)
+				params := buildUploadOptions(appID, versionCode, versionName, projectRoot, originalFile, overwrite)
 
-		err := server.ProcessFileRequest(
-			apiKey,
-			"/ndk-symbol",
-			params,
-			fileField,
-			originalFile,
-			opts,
-			logger,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to upload NDK symbol for %s: %w", originalFile, err)
-		}
+				err := server.ProcessFileRequest(
+					apiKey,
+					"/ndk-symbol",
+					params,
+					fileField,
+					originalFile,
+					opts,
+					logger,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to upload NDK symbol for %s: %w", originalFile, err)
+				}
+				return nil
+			},
+		})
 	}
 
-	return nil
+	return server.RunUploadJobs(jobs, opts.Concurrency, logger)
 }
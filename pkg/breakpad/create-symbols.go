@@ -0,0 +1,88 @@
+package breakpad
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// ProcessCreateBreakpadSymbols creates a Breakpad symbol file (.sym) for each ELF binary
+// found in the given paths, ready to be uploaded with `upload breakpad`.
+//
+// Parameters:
+// - globalOptions: CLI options including the paths to the binaries and the output directory.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if no binaries are found, or a symbol file cannot be created or written.
+func ProcessCreateBreakpadSymbols(globalOptions options.CLI, logger log.Logger) error {
+	createOptions := globalOptions.CreateBreakpadSymbols
+
+	fileList, err := utils.BuildFileList(createOptions.Path)
+	if err != nil {
+		return err
+	}
+
+	if createOptions.OutputDir != "" {
+		if err := os.MkdirAll(createOptions.OutputDir, 0755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+	}
+
+	created := 0
+	for _, file := range fileList {
+		isElf, err := utils.IsSymbolFile(file)
+		if err != nil || !isElf {
+			logger.Debug(fmt.Sprintf("Skipping %s - not an ELF binary", file))
+			continue
+		}
+
+		symbols, err := CreateSymbolFile(file, createOptions.OsName)
+		if err != nil {
+			return fmt.Errorf("creating symbol file for %s: %w", file, err)
+		}
+
+		if !symbols.HasDwarf {
+			logger.Warn(fmt.Sprintf("%s has no DWARF debug information, only public symbols will be included", file))
+		}
+
+		outputDir := createOptions.OutputDir
+		if outputDir == "" {
+			outputDir = filepath.Dir(file)
+		}
+		outputPath := filepath.Join(outputDir, filepath.Base(file)+".sym")
+
+		if err := writeSymbolFile(symbols, outputPath); err != nil {
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Created %s (MODULE %s %s %s %s)", outputPath,
+			symbols.Module.Os, symbols.Module.Arch, symbols.Module.Id, symbols.Module.Name))
+		created++
+	}
+
+	if created == 0 {
+		return fmt.Errorf("no ELF binaries found")
+	}
+
+	return nil
+}
+
+// writeSymbolFile writes a symbol file to the given path.
+func writeSymbolFile(symbols *SymbolFile, path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+
+	if _, err := symbols.WriteTo(out); err != nil {
+		out.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,74 @@
+package breakpad
+
+import (
+	"debug/dwarf"
+	"sort"
+
+	bugsnagElf "github.com/bugsnag/bugsnag-cli/pkg/elf"
+)
+
+// addDwarf adds FILE, FUNC and line records for the functions in the DWARF information.
+func (s *SymbolFile) addDwarf(data *dwarf.Data, base uint64) error {
+	info, err := bugsnagElf.ReadDwarf(data)
+	if err != nil {
+		return err
+	}
+
+	s.Files = info.Files
+
+	seen := map[uint64]bool{}
+	for _, function := range info.Functions {
+		name := function.Name
+		if name == "" {
+			name = "<name omitted>"
+		}
+
+		for _, r := range function.Ranges {
+			// Functions removed by the linker are left at address zero
+			if r[0] == 0 || r[1] <= r[0] || r[0] < base || seen[r[0]] {
+				continue
+			}
+			seen[r[0]] = true
+
+			s.Funcs = append(s.Funcs, Func{
+				Address: r[0] - base,
+				Size:    r[1] - r[0],
+				Name:    name,
+				Lines:   linesInRange(info.Rows, r[0], r[1], base),
+			})
+		}
+	}
+
+	sort.Slice(s.Funcs, func(i, j int) bool {
+		return s.Funcs[i].Address < s.Funcs[j].Address
+	})
+
+	return nil
+}
+
+// linesInRange returns the line records for the addresses from low up to high, relative
+// to the load address of the module.
+func linesInRange(rows []bugsnagElf.LineRow, low, high, base uint64) []Line {
+	var lines []Line
+
+	i := sort.Search(len(rows), func(i int) bool {
+		return rows[i].Address+rows[i].Size > low
+	})
+	for ; i < len(rows) && rows[i].Address < high; i++ {
+		row := rows[i]
+		start := max(row.Address, low)
+		end := min(row.Address+row.Size, high)
+		if end <= start {
+			continue
+		}
+
+		lines = append(lines, Line{
+			Address: start - base,
+			Size:    end - start,
+			Line:    row.Line,
+			File:    row.File,
+		})
+	}
+
+	return lines
+}
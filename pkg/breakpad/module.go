@@ -0,0 +1,72 @@
+package breakpad
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadModule reads the MODULE record from the first line of a Breakpad symbol file, along
+// with the code identifier and code file from any INFO CODE_ID record that follows it.
+//
+// Parameters:
+// - path: The path to the symbol file.
+//
+// Returns:
+// - The module that the symbol file describes.
+// - An error if the file cannot be read or does not start with a valid MODULE record.
+func ReadModule(path string) (*Module, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	module, err := parseModuleRecord(scanner.Text())
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a Breakpad symbol file: %w", path, err)
+	}
+
+	// INFO records directly follow the MODULE record
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "INFO" {
+			break
+		}
+		if len(fields) >= 3 && fields[1] == "CODE_ID" {
+			module.CodeId = fields[2]
+			if len(fields) > 3 {
+				module.CodeFile = strings.Join(fields[3:], " ")
+			}
+		}
+	}
+
+	return module, scanner.Err()
+}
+
+// parseModuleRecord parses a line of the form `MODULE <os> <arch> <id> <name>`. The name
+// is the remainder of the line, so it may contain spaces.
+func parseModuleRecord(line string) (*Module, error) {
+	fields := strings.SplitN(strings.TrimRight(line, "\r"), " ", 5)
+	if len(fields) < 5 || fields[0] != "MODULE" {
+		return nil, fmt.Errorf("missing MODULE record")
+	}
+
+	return &Module{
+		Os:   fields[1],
+		Arch: fields[2],
+		Id:   fields[3],
+		Name: fields[4],
+	}, nil
+}
@@ -0,0 +1,258 @@
+package breakpad
+
+import (
+	"bufio"
+	"debug/elf"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	bugsnagElf "github.com/bugsnag/bugsnag-cli/pkg/elf"
+)
+
+// Module identifies the binary that a symbol file describes, as written in its MODULE
+// and INFO CODE_ID records.
+type Module struct {
+	Os       string
+	Arch     string
+	Id       string
+	Name     string
+	CodeId   string
+	CodeFile string
+}
+
+// Func is a function with its address range and source line information.
+type Func struct {
+	Address uint64
+	Size    uint64
+	Name    string
+	Lines   []Line
+}
+
+// Line maps a range of addresses to a line in one of the symbol file's source files.
+type Line struct {
+	Address uint64
+	Size    uint64
+	Line    int
+	File    int
+}
+
+// Public is an exported symbol that has no debug information.
+type Public struct {
+	Address uint64
+	Name    string
+}
+
+// SymbolFile is the contents of a Breakpad text symbol file.
+type SymbolFile struct {
+	Module   Module
+	Files    []string
+	Funcs    []Func
+	Publics  []Public
+	HasDwarf bool
+}
+
+// CreateSymbolFile reads an ELF binary and its DWARF debug information and builds a
+// Breakpad symbol file from it, in the same way as Breakpad's dump_syms tool.
+//
+// Functions and line records are only created if the binary has DWARF debug information.
+// Symbols from the symbol table that are not covered by a function are written as
+// PUBLIC records.
+//
+// Parameters:
+// - path: The path to the ELF binary.
+// - osName: The operating system the binary was built for, e.g. "Linux".
+//
+// Returns:
+// - The symbol file for the binary.
+// - An error if the binary cannot be read or has no way of identifying it.
+func CreateSymbolFile(path string, osName string) (*SymbolFile, error) {
+	file, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ELF file: %w", err)
+	}
+	defer file.Close()
+
+	module, err := getModule(path, osName, file)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := &SymbolFile{Module: module}
+	base := loadAddress(file)
+
+	if dwarfData, err := file.DWARF(); err == nil {
+		symbols.HasDwarf = true
+		if err := symbols.addDwarf(dwarfData, base); err != nil {
+			return nil, fmt.Errorf("failed to read DWARF information from %s: %w", path, err)
+		}
+	}
+
+	symbols.addPublics(file, base)
+
+	return symbols, nil
+}
+
+// WriteTo writes the symbol file in the Breakpad text format.
+func (s *SymbolFile) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	out := bufio.NewWriter(counter)
+
+	fmt.Fprintf(out, "MODULE %s %s %s %s\n", s.Module.Os, s.Module.Arch, s.Module.Id, s.Module.Name)
+	if s.Module.CodeId != "" && s.Module.CodeFile != "" {
+		fmt.Fprintf(out, "INFO CODE_ID %s %s\n", s.Module.CodeId, s.Module.CodeFile)
+	} else if s.Module.CodeId != "" {
+		fmt.Fprintf(out, "INFO CODE_ID %s\n", s.Module.CodeId)
+	}
+	for i, file := range s.Files {
+		fmt.Fprintf(out, "FILE %d %s\n", i, file)
+	}
+	for _, function := range s.Funcs {
+		fmt.Fprintf(out, "FUNC %x %x 0 %s\n", function.Address, function.Size, function.Name)
+		for _, line := range function.Lines {
+			fmt.Fprintf(out, "%x %x %d %d\n", line.Address, line.Size, line.Line, line.File)
+		}
+	}
+	for _, public := range s.Publics {
+		fmt.Fprintf(out, "PUBLIC %x 0 %s\n", public.Address, public.Name)
+	}
+
+	err := out.Flush()
+	return counter.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// getModule builds the MODULE information for a binary. The debug identifier is derived
+// from the GNU build ID, or a hash of the start of the .text section if there is none.
+func getModule(path string, osName string, file *elf.File) (Module, error) {
+	module := Module{
+		Os:   osName,
+		Arch: getArch(file.Machine),
+		Name: filepath.Base(path),
+	}
+
+	var identifier []byte
+	if buildId, err := bugsnagElf.GetBuildId(path); err == nil {
+		identifier, _ = hex.DecodeString(buildId)
+		module.CodeId = strings.ToUpper(buildId)
+	}
+
+	if len(identifier) == 0 {
+		text := file.Section(".text")
+		if text == nil || text.Type == elf.SHT_NOBITS {
+			return module, fmt.Errorf("%s has no build ID or .text section to identify it", path)
+		}
+
+		// Breakpad XORs the first page of the .text section into a 16 byte identifier
+		data := make([]byte, min(text.Size, 4096))
+		if _, err := text.ReadAt(data, 0); err != nil {
+			return module, fmt.Errorf("failed to read .text section from %s: %w", path, err)
+		}
+		identifier = make([]byte, 16)
+		for i, b := range data {
+			identifier[i%16] ^= b
+		}
+	}
+
+	module.Id = debugIdentifier(identifier)
+	return module, nil
+}
+
+// debugIdentifier converts a build ID into a Breakpad debug identifier. The first 16
+// bytes are treated as a little-endian GUID and followed by an age of zero.
+func debugIdentifier(identifier []byte) string {
+	guid := make([]byte, 16)
+	copy(guid, identifier)
+
+	guid[0], guid[1], guid[2], guid[3] = guid[3], guid[2], guid[1], guid[0]
+	guid[4], guid[5] = guid[5], guid[4]
+	guid[6], guid[7] = guid[7], guid[6]
+
+	return strings.ToUpper(hex.EncodeToString(guid)) + "0"
+}
+
+// getArch converts an ELF machine type into the CPU architecture name used by Breakpad.
+func getArch(machine elf.Machine) string {
+	switch machine {
+	case elf.EM_386:
+		return "x86"
+	case elf.EM_X86_64:
+		return "x86_64"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_MIPS:
+		return "mips"
+	case elf.EM_PPC:
+		return "ppc"
+	case elf.EM_PPC64:
+		return "ppc64"
+	case elf.EM_RISCV:
+		return "riscv64"
+	case elf.EM_S390:
+		return "s390"
+	}
+	return strings.ToLower(strings.TrimPrefix(machine.String(), "EM_"))
+}
+
+// loadAddress returns the lowest virtual address of the binary's loadable segments.
+// Addresses in the symbol file are relative to it.
+func loadAddress(file *elf.File) uint64 {
+	var base uint64
+	found := false
+	for _, prog := range file.Progs {
+		if prog.Type == elf.PT_LOAD && (!found || prog.Vaddr < base) {
+			base = prog.Vaddr
+			found = true
+		}
+	}
+	return base
+}
+
+// addPublics adds a PUBLIC record for every function in the symbol table that is not
+// already covered by a FUNC record.
+func (s *SymbolFile) addPublics(file *elf.File, base uint64) {
+	seen := map[uint64]bool{}
+	for _, symbol := range bugsnagElf.FunctionSymbols(file) {
+		address := symbol.Value
+		if address < base {
+			continue
+		}
+		address -= base
+
+		if seen[address] || s.findFunc(address) != nil {
+			continue
+		}
+		seen[address] = true
+		s.Publics = append(s.Publics, Public{Address: address, Name: symbol.Name})
+	}
+
+	sort.Slice(s.Publics, func(i, j int) bool {
+		return s.Publics[i].Address < s.Publics[j].Address
+	})
+}
+
+// findFunc returns the function containing an address, or nil if there is none.
+func (s *SymbolFile) findFunc(address uint64) *Func {
+	i := sort.Search(len(s.Funcs), func(i int) bool {
+		return s.Funcs[i].Address+s.Funcs[i].Size > address
+	})
+	if i < len(s.Funcs) && s.Funcs[i].Address <= address {
+		return &s.Funcs[i]
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Key returns the key an upload is recorded under in the ledger. Uploads have the same
+// key only if they are for the same project, endpoint and fields, with identical files.
+//
+// Parameters:
+// - apiKey: The project API key.
+// - endpoint: The URL the files are uploaded to.
+// - fields: The form fields sent with the files.
+// - files: The SHA-256 of each file's contents, keyed by its form field.
+//
+// Returns:
+// - string: The hex-encoded key.
+func Key(apiKey string, endpoint string, fields map[string]string, files map[string]string) string {
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "apiKey=%q\nendpoint=%q\n", apiKey, endpoint)
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		fmt.Fprintf(hash, "field %q=%q\n", name, fields[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		fmt.Fprintf(hash, "file %q=%q\n", name, files[name])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
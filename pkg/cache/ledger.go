@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The file extension of the entries recorded in the ledger, and the prefix and extension
+// of the temporary files they are written to.
+const (
+	entryExt   = ".json"
+	tempPrefix = "entry-"
+	tempExt    = ".tmp"
+)
+
+// Entry records a successful upload.
+type Entry struct {
+	File       string    `json:"file"`
+	Endpoint   string    `json:"endpoint"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// Ledger is a persistent record of successful uploads, stored as one file per upload in
+// the cache directory so that it can be safely shared by concurrent uploads and processes.
+type Ledger struct {
+	dir string
+}
+
+// DefaultDir returns the directory used for the ledger when none is given, which is
+// within the user's cache directory (e.g. ~/.cache/bugsnag-cli/uploads on Linux).
+func DefaultDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate the user cache directory, please specify using `--cache-dir`: %w", err)
+	}
+	return filepath.Join(cacheDir, "bugsnag-cli", "uploads"), nil
+}
+
+// Open opens the ledger in the given directory, creating the directory if needed.
+//
+// Parameters:
+// - dir: The cache directory, or an empty string to use DefaultDir.
+//
+// Returns:
+// - *Ledger: The ledger.
+// - error: An error if the directory cannot be found or created.
+func Open(dir string) (*Ledger, error) {
+	if dir == "" {
+		var err error
+		dir, err = DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cache directory %s: %w", dir, err)
+	}
+
+	return &Ledger{dir: dir}, nil
+}
+
+// Dir returns the directory the ledger is stored in.
+func (l *Ledger) Dir() string {
+	return l.dir
+}
+
+// Get returns the entry recorded for an upload, if there is one.
+func (l *Ledger) Get(key string) (Entry, bool) {
+	var entry Entry
+
+	data, err := os.ReadFile(l.path(key))
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// Record stores an entry for a successful upload, replacing any existing entry.
+func (l *Ledger) Record(key string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it so that a partially written entry is never read
+	tempFile, err := os.CreateTemp(l.dir, tempPrefix+"*"+tempExt)
+	if err != nil {
+		return fmt.Errorf("recording upload in cache: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("recording upload in cache: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("recording upload in cache: %w", err)
+	}
+
+	if err := os.Rename(tempFile.Name(), l.path(key)); err != nil {
+		return fmt.Errorf("recording upload in cache: %w", err)
+	}
+
+	return nil
+}
+
+// Prune removes entries that were recorded more than olderThan ago, along with any
+// leftover temporary files. An olderThan of zero removes every entry.
+//
+// Parameters:
+// - olderThan: The age after which entries are removed.
+//
+// Returns:
+// - int: The number of entries removed.
+// - error: An error if the cache directory cannot be read or an entry cannot be removed.
+func (l *Ledger) Prune(olderThan time.Duration) (int, error) {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return 0, fmt.Errorf("reading cache directory %s: %w", l.dir, err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		// Only remove files created by the ledger, in case the directory is shared
+		isEntry := isKey(strings.TrimSuffix(file.Name(), entryExt)) && strings.HasSuffix(file.Name(), entryExt)
+		isTemp := strings.HasPrefix(file.Name(), tempPrefix) && strings.HasSuffix(file.Name(), tempExt)
+		if !isEntry && !isTemp {
+			continue
+		}
+		if olderThan > 0 && info.ModTime().After(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(l.dir, file.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("removing %s from cache: %w", file.Name(), err)
+		}
+		if isEntry {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// isKey reports whether name is a key created by Key.
+func isKey(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+func (l *Ledger) path(key string) string {
+	return filepath.Join(l.dir, key+entryExt)
+}
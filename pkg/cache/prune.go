@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+)
+
+// ProcessCachePrune removes entries from the upload cache that are older than the
+// configured age, so that the files they record are uploaded again next time.
+//
+// Parameters:
+// - globalOptions: CLI options including the cache directory and maximum age.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if the cache cannot be opened or an entry cannot be removed.
+func ProcessCachePrune(globalOptions options.CLI, logger log.Logger) error {
+	pruneOptions := globalOptions.Cache.Prune
+
+	ledger, err := Open(pruneOptions.CacheDir)
+	if err != nil {
+		return err
+	}
+
+	if globalOptions.DryRun {
+		logger.Info(fmt.Sprintf("(dryrun) Skipping pruning of the upload cache in %s", ledger.Dir()))
+		return nil
+	}
+
+	removed, err := ledger.Prune(pruneOptions.OlderThan)
+	if err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Removed %d entries from the upload cache in %s", removed, ledger.Dir()))
+	return nil
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+const (
+	// FileName is the name of the project configuration file.
+	FileName = ".bugsnag.yml"
+	// EnvPrefix is prepended to a flag's name to form the environment variable that sets it,
+	// e.g. BUGSNAG_API_KEY for --api-key.
+	EnvPrefix = "BUGSNAG_"
+)
+
+// Sources of a resolved option value, as reported by Resolver.Source.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceConfig  = "config"
+	SourceDefault = "default"
+)
+
+// ignoredFlags are never resolved from the environment or the configuration file.
+var ignoredFlags = []string{"help", "version"}
+
+// Resolver is a kong.Resolver that fills in options that were not passed on the
+// command line from environment variables and the project configuration file.
+//
+// Environment variables take precedence over the configuration file. Within the file,
+// options can be set at the top level or in a section for a command, e.g.
+//
+//	api-key: YOUR_API_KEY
+//	upload:
+//	  retries: 3
+//	  android-ndk:
+//	    variant: release
+//
+// The section for the selected command is searched first, followed by each of its
+// parent sections and then the top level.
+type Resolver struct {
+	// Path is the configuration file that was loaded, or empty if none was found.
+	Path    string
+	values  map[string]interface{}
+	sources map[string]string
+}
+
+// Load finds the configuration file by walking up from dir towards the root of the
+// file system and creates a resolver from it. A resolver is returned even if no
+// configuration file is found, so that environment variables are still applied.
+//
+// Parameters:
+//   - dir: The directory to start searching from, usually the working directory.
+//
+// Returns:
+//   - *Resolver: The resolver for the configuration file and environment.
+//   - error: An error if the configuration file exists but cannot be read or parsed.
+func Load(dir string) (*Resolver, error) {
+	resolver := &Resolver{
+		values:  map[string]interface{}{},
+		sources: map[string]string{},
+	}
+
+	path := findConfigFile(dir)
+	if path == "" {
+		return resolver, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &resolver.values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if resolver.values == nil {
+		resolver.values = map[string]interface{}{}
+	}
+
+	resolver.Path = path
+	return resolver, nil
+}
+
+// findConfigFile returns the path of the nearest configuration file in dir or one of
+// its parents, or an empty string if there is none.
+func findConfigFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// EnvVarName returns the environment variable that sets the flag with the given name.
+func EnvVarName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func (r *Resolver) Validate(app *kong.Application) error {
+	return nil
+}
+
+func (r *Resolver) Resolve(context *kong.Context, parent *kong.Path, flag *kong.Flag) (interface{}, error) {
+	if slices.Contains(ignoredFlags, flag.Name) {
+		return nil, nil
+	}
+
+	envVar := EnvVarName(flag.Name)
+	if value, ok := os.LookupEnv(envVar); ok && value != "" {
+		r.sources[flag.Name] = fmt.Sprintf("%s %s", SourceEnv, envVar)
+		return value, nil
+	}
+
+	sections := commandSections(context)
+	for i := len(sections); i >= 0; i-- {
+		value, ok := lookup(r.values, sections[:i], flag.Name)
+		if ok {
+			r.sources[flag.Name] = fmt.Sprintf("%s %s", SourceConfig, r.Path)
+			return normalizeValue(value), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Source returns where the value of a flag came from: the command line, an
+// environment variable, the configuration file or the flag's default. It returns an
+// empty string if the flag was not set, meaning the value is left to auto-detection.
+func (r *Resolver) Source(context *kong.Context, flag *kong.Flag) string {
+	for _, path := range context.Path {
+		if path.Flag == flag && !path.Resolved {
+			return SourceFlag
+		}
+	}
+
+	if source, ok := r.sources[flag.Name]; ok {
+		return source
+	}
+
+	if flag.HasDefault {
+		return SourceDefault
+	}
+
+	return ""
+}
+
+// commandSections returns the names of the selected command and its parents, from the
+// top-level command down, e.g. ["upload", "android-ndk"].
+func commandSections(context *kong.Context) []string {
+	var sections []string
+	for node := context.Selected(); node != nil; node = node.Parent {
+		if node.Type == kong.CommandNode {
+			sections = append([]string{node.Name}, sections...)
+		}
+	}
+	return sections
+}
+
+// lookup finds the value of a flag in the given section of the configuration,
+// accepting both kebab-case (api-key) and snake_case (api_key) keys.
+func lookup(values map[string]interface{}, sections []string, name string) (interface{}, bool) {
+	for _, section := range sections {
+		next, ok := values[section].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		values = next
+	}
+
+	for _, key := range []string{name, strings.ReplaceAll(name, "-", "_")} {
+		if value, ok := values[key]; ok && value != nil {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// normalizeValue converts a YAML value into the form kong expects from a resolver:
+// scalars become strings, lists become lists of strings and maps become maps of strings.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = fmt.Sprint(item)
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = fmt.Sprint(item)
+		}
+		return m
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// LogResolvedOptions logs the value of each option for the selected command that has
+// been set, along with where the value came from. Options that have not been set are
+// left to be detected automatically, and are not logged.
+//
+// Parameters:
+//   - context: The parsed kong context.
+//   - logger: Logger used to output the options.
+func (r *Resolver) LogResolvedOptions(context *kong.Context, logger log.Logger) {
+	if r.Path != "" {
+		logger.Info(fmt.Sprintf("Using configuration file %s", r.Path))
+	}
+
+	logger.Info("Resolved options:")
+	for _, path := range context.Path {
+		for _, flag := range path.Flags {
+			if flag.Hidden || slices.Contains(ignoredFlags, flag.Name) {
+				continue
+			}
+
+			source := r.Source(context, flag)
+			if source == "" {
+				continue
+			}
+
+			logger.Info(fmt.Sprintf("  --%s=%v (%s)", flag.Name, context.FlagValue(flag), source))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package debugid
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+)
+
+// InjectDebugId writes a debug ID into a bundle and its source map. An ID that is already in
+// the bundle, or failing that in the source map, is kept so that the command can be run more
+// than once; otherwise a new ID is derived from the contents of the bundle.
+//
+// Parameters:
+// - bundlePath: path to the bundle.
+// - sourceMapPath: path to the bundle's source map.
+// - dryRun: log the debug ID without writing it to either file.
+// - logger: logger instance.
+//
+// Returns:
+// - string: The debug ID of the bundle.
+// - error: if either file cannot be read or written.
+func InjectDebugId(bundlePath string, sourceMapPath string, dryRun bool, logger log.Logger) (string, error) {
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read bundle file %s: %w", bundlePath, err)
+	}
+	sourceMap, err := os.ReadFile(sourceMapPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read source map %s: %w", sourceMapPath, err)
+	}
+	var sourceMapContents map[string]interface{}
+	if err := json.Unmarshal(sourceMap, &sourceMapContents); err != nil {
+		return "", fmt.Errorf("cannot unmarshal source map %s: %w", sourceMapPath, err)
+	}
+
+	bundleDebugId := sourcemap.BundleDebugId(bundle)
+	sourceMapDebugId := sourcemap.SourceMapDebugId(sourceMapContents)
+
+	debugId := bundleDebugId
+	if debugId == "" {
+		debugId = sourceMapDebugId
+	}
+	if debugId == "" {
+		debugId = sourcemap.GenerateDebugId(bundle)
+	}
+
+	if bundleDebugId == debugId && sourceMapContents["debugId"] == debugId {
+		logger.Info(fmt.Sprintf("%s already has the debug ID %s", bundlePath, debugId))
+		return debugId, nil
+	}
+	if sourceMapDebugId != "" && sourceMapDebugId != debugId {
+		logger.Warn(fmt.Sprintf("Replacing the debug ID %s in %s with the debug ID of its bundle", sourceMapDebugId, sourceMapPath))
+	}
+
+	if dryRun {
+		logger.Info(fmt.Sprintf("(dryrun) Skipping injecting the debug ID %s into %s and %s", debugId, bundlePath, sourceMapPath))
+		return debugId, nil
+	}
+
+	if bundleDebugId == "" {
+		if err := writeFile(bundlePath, sourcemap.InjectBundleDebugId(bundle, debugId)); err != nil {
+			return "", err
+		}
+	}
+	if sourceMapContents["debugId"] != debugId {
+		injected, err := sourcemap.InjectSourceMapDebugId(sourceMap, debugId)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", sourceMapPath, err)
+		}
+		if err := writeFile(sourceMapPath, injected); err != nil {
+			return "", err
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Injected the debug ID %s into %s and %s", debugId, bundlePath, sourceMapPath))
+	return debugId, nil
+}
+
+// writeFile replaces the contents of a file, keeping its permissions.
+func writeFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ProcessInjectDebugIds injects a debug ID into every bundle and source map found at the
+// given paths, in the same way that `upload js` finds them.
+//
+// Parameters:
+// - globalOptions: CLI options including the paths to the bundles and source maps.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if no source maps are found or a debug ID cannot be injected.
+func ProcessInjectDebugIds(globalOptions options.CLI, logger log.Logger) error {
+	injectOptions := globalOptions.InjectDebugIds
+
+	injected := 0
+	for _, path := range injectOptions.Path {
+		// If the path is a .map file, treat the path as the source map itself
+		sourceMapPath, outputPath := upload.ResolveSourceMapArgument(path, injectOptions.SourceMap)
+
+		sourceMapBundles, err := upload.ResolveSourceMapPaths(sourceMapPath, injectOptions.Bundle, outputPath, logger)
+		if err != nil {
+			return err
+		}
+
+		for _, bundle := range sourceMapBundles {
+			if bundle.BundlePath == "" {
+				logger.Warn(fmt.Sprintf("Skipping %s as its bundle could not be found, please specify the path by using --bundle", bundle.SourceMapPath))
+				continue
+			}
+			if _, err := InjectDebugId(bundle.BundlePath, bundle.SourceMapPath, globalOptions.DryRun, logger); err != nil {
+				return err
+			}
+			injected++
+		}
+	}
+
+	if injected == 0 {
+		return fmt.Errorf("could not find a bundle and source map, please specify the paths by using --bundle and --source-map")
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package elf
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"errors"
+	"io"
+	"sort"
+)
+
+// DebugInfo is the functions and line table read from the DWARF information of a binary.
+type DebugInfo struct {
+	// The functions that have code, in the order they were found, with the calls inlined
+	// into each of them
+	Functions []*DwarfFunction
+	// The line table of every compilation unit, sorted by address
+	Rows []LineRow
+	// The source files referred to by the line table, in the order they were first used
+	Files []string
+}
+
+// DwarfFunction is a function, or a call to a function that was inlined into another, with
+// the address ranges of its code.
+type DwarfFunction struct {
+	// The qualified name, including any namespaces and classes, or empty if it has none
+	Name   string
+	Ranges [][2]uint64
+
+	// The source location of the call, for inlined functions
+	CallFile   string
+	CallLine   int
+	CallColumn int
+
+	Inlined []*DwarfFunction
+
+	// The declaration that the function's name is read from if it is not named itself
+	ref dwarf.Offset
+}
+
+// LineRow is a range of addresses that map to a single source line.
+type LineRow struct {
+	Address uint64
+	Size    uint64
+	// The index of the source file in DebugInfo.Files
+	File   int
+	Line   int
+	Column int
+}
+
+// dwarfReader walks the entries of the DWARF information, keeping track of the
+// qualified name prefix and the function that each entry is in.
+type dwarfReader struct {
+	data  *dwarf.Data
+	info  *DebugInfo
+	files map[string]int
+
+	// names holds the qualified name of every named entry that a function may refer to
+	// through DW_AT_specification or DW_AT_abstract_origin, and refs the entries that
+	// instead refer to another entry for their name.
+	names map[dwarf.Offset]string
+	refs  map[dwarf.Offset]dwarf.Offset
+}
+
+// ReadDwarf reads the functions, inlined calls and line table of every compilation unit in
+// DWARF information.
+//
+// Parameters:
+// - data: The DWARF information of a binary.
+//
+// Returns:
+// - *DebugInfo: The functions and line table.
+// - error: An error if the DWARF information cannot be read.
+func ReadDwarf(data *dwarf.Data) (*DebugInfo, error) {
+	reader := &dwarfReader{
+		data:  data,
+		info:  &DebugInfo{},
+		files: map[string]int{},
+		names: map[dwarf.Offset]string{},
+		refs:  map[dwarf.Offset]dwarf.Offset{},
+	}
+
+	scopes, err := reader.read()
+	if err != nil {
+		return nil, err
+	}
+
+	// Names can only be resolved once every declaration has been read
+	for _, scope := range scopes {
+		if scope.Name == "" {
+			scope.Name = reader.resolveName(scope.ref)
+		}
+	}
+
+	sort.SliceStable(reader.info.Rows, func(i, j int) bool {
+		return reader.info.Rows[i].Address < reader.info.Rows[j].Address
+	})
+	return reader.info, nil
+}
+
+// read walks every compilation unit, recording its line table and the tree of functions
+// and inlined calls within it. It returns every function and inlined call that was found.
+func (r *dwarfReader) read() ([]*DwarfFunction, error) {
+	entries := r.data.Reader()
+	var scopes []*DwarfFunction
+
+	// Each entry with children pushes the qualified name prefix and the function that
+	// its children are in
+	var prefixes []string
+	var parents []*DwarfFunction
+	prefix := func() string {
+		if len(prefixes) == 0 {
+			return ""
+		}
+		return prefixes[len(prefixes)-1]
+	}
+	parent := func() *DwarfFunction {
+		if len(parents) == 0 {
+			return nil
+		}
+		return parents[len(parents)-1]
+	}
+
+	var files []*dwarf.LineFile
+	for {
+		entry, err := entries.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return scopes, nil
+		}
+
+		if entry.Tag == 0 {
+			if len(prefixes) > 0 {
+				prefixes = prefixes[:len(prefixes)-1]
+				parents = parents[:len(parents)-1]
+			}
+			continue
+		}
+
+		name, _ := entry.Val(dwarf.AttrName).(string)
+		qualified := name
+		if name != "" && prefix() != "" {
+			qualified = prefix() + "::" + name
+		}
+
+		childPrefix, childParent := prefix(), parent()
+		switch entry.Tag {
+		case dwarf.TagCompileUnit:
+			prefixes, parents = nil, nil
+			childPrefix, childParent = "", nil
+			if files, err = r.readLines(entry); err != nil {
+				return nil, err
+			}
+		case dwarf.TagNamespace:
+			if name == "" {
+				qualified = prefix() + "::(anonymous namespace)"
+				if prefix() == "" {
+					qualified = "(anonymous namespace)"
+				}
+			}
+			childPrefix = qualified
+		case dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType:
+			if name != "" {
+				childPrefix = qualified
+			}
+		case dwarf.TagSubprogram, dwarf.TagInlinedSubroutine:
+			scope := &DwarfFunction{Name: qualified}
+			for _, attr := range []dwarf.Attr{dwarf.AttrSpecification, dwarf.AttrAbstractOrigin} {
+				if ref, ok := entry.Val(attr).(dwarf.Offset); ok {
+					scope.ref = ref
+					if qualified == "" {
+						r.refs[entry.Offset] = ref
+					}
+					break
+				}
+			}
+			scope.Ranges, _ = r.data.Ranges(entry)
+			childParent = scope
+			scopes = append(scopes, scope)
+
+			if entry.Tag == dwarf.TagSubprogram {
+				if len(scope.Ranges) > 0 {
+					r.info.Functions = append(r.info.Functions, scope)
+				}
+			} else if parent() != nil {
+				if index, ok := entry.Val(dwarf.AttrCallFile).(int64); ok && index >= 0 && int(index) < len(files) && files[index] != nil {
+					scope.CallFile = files[index].Name
+				}
+				callLine, _ := entry.Val(dwarf.AttrCallLine).(int64)
+				callColumn, _ := entry.Val(dwarf.AttrCallColumn).(int64)
+				scope.CallLine, scope.CallColumn = int(callLine), int(callColumn)
+				parent().Inlined = append(parent().Inlined, scope)
+			}
+		}
+
+		if name != "" {
+			r.names[entry.Offset] = qualified
+		}
+
+		if entry.Children {
+			prefixes = append(prefixes, childPrefix)
+			parents = append(parents, childParent)
+		}
+	}
+}
+
+// resolveName returns the qualified name of the declaration that a function refers to,
+// following references until a named entry is found.
+func (r *dwarfReader) resolveName(ref dwarf.Offset) string {
+	for depth := 0; depth < 8 && ref != 0; depth++ {
+		if name, ok := r.names[ref]; ok {
+			return name
+		}
+		ref = r.refs[ref]
+	}
+	return ""
+}
+
+// readLines records every row of a compilation unit's line table, returning its file table.
+func (r *dwarfReader) readLines(unit *dwarf.Entry) ([]*dwarf.LineFile, error) {
+	lines, err := r.data.LineReader(unit)
+	if err != nil || lines == nil {
+		return nil, err
+	}
+
+	var previous *dwarf.LineEntry
+	for {
+		var entry dwarf.LineEntry
+		err := lines.Next(&entry)
+		if errors.Is(err, io.EOF) {
+			return lines.Files(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if previous != nil && entry.Address > previous.Address && previous.File != nil && previous.Line > 0 {
+			r.info.Rows = append(r.info.Rows, LineRow{
+				Address: previous.Address,
+				Size:    entry.Address - previous.Address,
+				File:    r.fileIndex(previous.File.Name),
+				Line:    previous.Line,
+				Column:  previous.Column,
+			})
+		}
+
+		if entry.EndSequence {
+			previous = nil
+		} else {
+			previous = &entry
+		}
+	}
+}
+
+// fileIndex returns the index of a source file in DebugInfo.Files, adding it if needed.
+func (r *dwarfReader) fileIndex(name string) int {
+	index, ok := r.files[name]
+	if !ok {
+		index = len(r.info.Files)
+		r.files[name] = index
+		r.info.Files = append(r.info.Files, name)
+	}
+	return index
+}
+
+// FunctionSymbols returns the defined functions in the symbol table of a binary, or its
+// dynamic symbol table if it has no other, sorted by address. The lowest bit that marks
+// Thumb functions on 32-bit ARM is cleared from their addresses.
+//
+// Parameters:
+// - file: The ELF binary.
+//
+// Returns:
+// - []elf.Symbol: The function symbols.
+func FunctionSymbols(file *elf.File) []elf.Symbol {
+	symbols, err := file.Symbols()
+	if err != nil || len(symbols) == 0 {
+		symbols, _ = file.DynamicSymbols()
+	}
+
+	var functions []elf.Symbol
+	for _, symbol := range symbols {
+		if elf.ST_TYPE(symbol.Info) != elf.STT_FUNC || symbol.Section == elf.SHN_UNDEF || symbol.Value == 0 || symbol.Name == "" {
+			continue
+		}
+		if file.Machine == elf.EM_ARM {
+			// Thumb functions have the lowest bit set
+			symbol.Value &^= 1
+		}
+		functions = append(functions, symbol)
+	}
+
+	sort.SliceStable(functions, func(i, j int) bool {
+		return functions[i].Value < functions[j].Value
+	})
+	return functions
+}
@@ -0,0 +1,413 @@
+package elf
+
+import (
+	"bufio"
+	"compress/zlib"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// elfHeader holds the fields of an ELF file header, widened to 64 bits so that
+// 32 and 64-bit files can be handled in the same way.
+type elfHeader struct {
+	Ident     [elf.EI_NIDENT]byte
+	Type      uint16
+	Machine   uint16
+	Version   uint32
+	Entry     uint64
+	Phoff     uint64
+	Shoff     uint64
+	Flags     uint32
+	Ehsize    uint16
+	Phentsize uint16
+	Phnum     uint16
+	Shentsize uint16
+	Shnum     uint16
+	Shstrndx  uint16
+}
+
+// elfSection holds the fields of a section header, widened to 64 bits.
+type elfSection struct {
+	Name      uint32
+	Type      uint32
+	Flags     uint64
+	Addr      uint64
+	Offset    uint64
+	Size      uint64
+	Link      uint32
+	Info      uint32
+	Addralign uint64
+	Entsize   uint64
+}
+
+// elfProg holds the fields of a program header, widened to 64 bits.
+type elfProg struct {
+	Type   uint32
+	Flags  uint32
+	Off    uint64
+	Vaddr  uint64
+	Paddr  uint64
+	Filesz uint64
+	Memsz  uint64
+	Align  uint64
+}
+
+// ExtractDebugSections writes a copy of an ELF file that contains only the information
+// needed to symbolicate it, equivalent to running
+// `objcopy --only-keep-debug --compress-debug-sections=zlib`.
+//
+// All section and program headers are kept so that addresses and section indexes are
+// unchanged, but the contents of loadable sections are dropped, with the exception of
+// notes such as the GNU build ID. Non-loadable sections such as the symbol table are
+// copied as-is and `.debug_*` sections are compressed with zlib.
+//
+// Parameters:
+//
+//	inputPath  - the full file path to the ELF binary.
+//	outputPath - the path to write the debug file to.
+//
+// Returns:
+//
+//	error - non-nil if the input could not be read or the output could not be written.
+func ExtractDebugSections(inputPath, outputPath string) error {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open ELF file: %w", err)
+	}
+	defer input.Close()
+
+	file, err := elf.NewFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to parse ELF file %s: %w", inputPath, err)
+	}
+
+	header, sections, progs, err := readElfHeaders(input, file)
+	if err != nil {
+		return fmt.Errorf("failed to read ELF headers from %s: %w", inputPath, err)
+	}
+
+	is64 := file.Class == elf.ELFCLASS64
+	order := file.ByteOrder
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to write debug file %s: %w", outputPath, err)
+	}
+	err = writeDebugFile(input, file, output, header, sections, progs, is64, order)
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("failed to write debug file %s from %s: %w", outputPath, inputPath, err)
+	}
+
+	return nil
+}
+
+// offsetWriter keeps track of the offset in the output file as it is written.
+type offsetWriter struct {
+	writer io.Writer
+	offset uint64
+}
+
+func (ow *offsetWriter) Write(p []byte) (int, error) {
+	n, err := ow.writer.Write(p)
+	ow.offset += uint64(n)
+	return n, err
+}
+
+// padTo writes zeros up to the given offset.
+func (ow *offsetWriter) padTo(offset uint64) error {
+	_, err := io.CopyN(ow, zeroReader{}, int64(offset-ow.offset))
+	return err
+}
+
+// zeroReader reads an endless stream of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// writeDebugFile writes the debug file. The section contents are streamed from the input
+// in order, followed by the section headers, and then the file and program headers are
+// written at the start of the file once the offsets of the sections are known. Only one
+// section is read at a time, so the memory used does not depend on the size of the file.
+func writeDebugFile(input io.ReaderAt, file *elf.File, output *os.File, header elfHeader, sections []elfSection, progs []elfProg, is64 bool, order binary.ByteOrder) error {
+	var ehsize, phentsize, shentsize uint64 = 52, 32, 40
+	if is64 {
+		ehsize, phentsize, shentsize = 64, 56, 64
+	}
+
+	// Lay out the output: the file header, program headers, section contents and
+	// finally the section headers.
+	buffered := bufio.NewWriter(output)
+	out := &offsetWriter{writer: buffered}
+
+	header.Phoff = 0
+	headersEnd := ehsize
+	if len(progs) > 0 {
+		header.Phoff = ehsize
+		headersEnd += phentsize * uint64(len(progs))
+	}
+	if err := out.padTo(headersEnd); err != nil {
+		return err
+	}
+
+	hasContents := make([]bool, len(sections))
+	for i := range sections {
+		if i == 0 {
+			continue
+		}
+		section := &sections[i]
+		if err := writeDebugSection(out, input, file.Sections[i], section, is64, order); err != nil {
+			return fmt.Errorf("failed to process section %s: %w", file.Sections[i].Name, err)
+		}
+		hasContents[i] = elf.SectionType(section.Type) != elf.SHT_NOBITS
+	}
+
+	header.Shoff = alignTo(out.offset, 8)
+	header.Ehsize = uint16(ehsize)
+	header.Phentsize = uint16(phentsize)
+	header.Shentsize = uint16(shentsize)
+
+	if err := out.padTo(header.Shoff); err != nil {
+		return err
+	}
+	for _, section := range sections {
+		if err := writeElfSection(out, section, is64, order); err != nil {
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	// Segments no longer have any contents in the file, but are kept so that the
+	// load address of the library can still be determined. Note segments are pointed
+	// at the copied note sections so that the build ID can still be read from them.
+	for i := range progs {
+		prog := &progs[i]
+		start, end := prog.Vaddr, prog.Vaddr+prog.Filesz
+		prog.Off = 0
+		prog.Filesz = 0
+
+		if elf.ProgType(prog.Type) != elf.PT_NOTE {
+			continue
+		}
+		for j, section := range sections {
+			if !hasContents[j] || elf.SectionType(section.Type) != elf.SHT_NOTE ||
+				section.Addr < start || section.Addr+section.Size > end {
+				continue
+			}
+			if prog.Filesz == 0 {
+				prog.Off = section.Offset
+			}
+			prog.Filesz = section.Offset + section.Size - prog.Off
+		}
+	}
+
+	if _, err := output.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	buffered.Reset(output)
+	if err := writeElfHeader(buffered, header, is64, order); err != nil {
+		return err
+	}
+	for _, prog := range progs {
+		if err := writeElfProg(buffered, prog, is64, order); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// writeDebugSection writes the contents of a section as it should appear in the debug
+// file, updating its header with its offset and size in the output.
+func writeDebugSection(out *offsetWriter, input io.ReaderAt, s *elf.Section, header *elfSection, is64 bool, order binary.ByteOrder) error {
+	if s.Type == elf.SHT_NOBITS {
+		header.Offset = alignTo(out.offset, header.Addralign)
+		return nil
+	}
+
+	// Loadable sections are only needed at runtime, other than notes such as the build ID
+	if s.Flags&elf.SHF_ALLOC != 0 && s.Type != elf.SHT_NOTE {
+		header.Type = uint32(elf.SHT_NOBITS)
+		header.Offset = alignTo(out.offset, header.Addralign)
+		return nil
+	}
+
+	contents := io.NewSectionReader(input, int64(header.Offset), int64(header.Size))
+	compress := strings.HasPrefix(s.Name, ".debug_") && s.Flags&elf.SHF_COMPRESSED == 0 && header.Size > 0
+
+	originalAlign := header.Addralign
+	if compress {
+		header.Flags |= uint64(elf.SHF_COMPRESSED)
+		header.Addralign = 4
+		if is64 {
+			header.Addralign = 8
+		}
+	}
+
+	header.Offset = alignTo(out.offset, header.Addralign)
+	if err := out.padTo(header.Offset); err != nil {
+		return err
+	}
+
+	var err error
+	if compress {
+		err = compressSection(out, contents, header.Size, originalAlign, is64, order)
+	} else {
+		_, err = io.Copy(out, contents)
+	}
+	if err != nil {
+		return err
+	}
+
+	header.Size = out.offset - header.Offset
+	return nil
+}
+
+// compressSection streams the contents of a section through zlib, prefixing it with an
+// ELF compression header.
+func compressSection(out io.Writer, contents io.Reader, size uint64, addralign uint64, is64 bool, order binary.ByteOrder) error {
+	if addralign == 0 {
+		addralign = 1
+	}
+
+	var err error
+	if is64 {
+		err = binary.Write(out, order, elf.Chdr64{
+			Type:      uint32(elf.COMPRESS_ZLIB),
+			Size:      size,
+			Addralign: addralign,
+		})
+	} else {
+		err = binary.Write(out, order, elf.Chdr32{
+			Type:      uint32(elf.COMPRESS_ZLIB),
+			Size:      uint32(size),
+			Addralign: uint32(addralign),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	writer := zlib.NewWriter(out)
+	if _, err := io.Copy(writer, contents); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// readElfHeaders reads the raw file, section and program headers of an ELF file.
+func readElfHeaders(input io.ReaderAt, file *elf.File) (elfHeader, []elfSection, []elfProg, error) {
+	var header elfHeader
+	order := file.ByteOrder
+	reader := io.NewSectionReader(input, 0, 1<<63-1)
+
+	if file.Class == elf.ELFCLASS64 {
+		var raw elf.Header64
+		if err := binary.Read(reader, order, &raw); err != nil {
+			return header, nil, nil, err
+		}
+		header = elfHeader{raw.Ident, raw.Type, raw.Machine, raw.Version, raw.Entry, raw.Phoff, raw.Shoff,
+			raw.Flags, raw.Ehsize, raw.Phentsize, raw.Phnum, raw.Shentsize, raw.Shnum, raw.Shstrndx}
+	} else {
+		var raw elf.Header32
+		if err := binary.Read(reader, order, &raw); err != nil {
+			return header, nil, nil, err
+		}
+		header = elfHeader{raw.Ident, raw.Type, raw.Machine, raw.Version, uint64(raw.Entry), uint64(raw.Phoff),
+			uint64(raw.Shoff), raw.Flags, raw.Ehsize, raw.Phentsize, raw.Phnum, raw.Shentsize, raw.Shnum, raw.Shstrndx}
+	}
+
+	if header.Shnum == 0 || int(header.Shnum) != len(file.Sections) || int(header.Phnum) != len(file.Progs) {
+		return header, nil, nil, fmt.Errorf("unsupported section or program header layout")
+	}
+
+	sections := make([]elfSection, header.Shnum)
+	for i := range sections {
+		reader.Seek(int64(header.Shoff)+int64(i)*int64(header.Shentsize), io.SeekStart)
+		if file.Class == elf.ELFCLASS64 {
+			var raw elf.Section64
+			if err := binary.Read(reader, order, &raw); err != nil {
+				return header, nil, nil, err
+			}
+			sections[i] = elfSection{raw.Name, raw.Type, raw.Flags, raw.Addr, raw.Off, raw.Size,
+				raw.Link, raw.Info, raw.Addralign, raw.Entsize}
+		} else {
+			var raw elf.Section32
+			if err := binary.Read(reader, order, &raw); err != nil {
+				return header, nil, nil, err
+			}
+			sections[i] = elfSection{raw.Name, raw.Type, uint64(raw.Flags), uint64(raw.Addr), uint64(raw.Off),
+				uint64(raw.Size), raw.Link, raw.Info, uint64(raw.Addralign), uint64(raw.Entsize)}
+		}
+	}
+
+	progs := make([]elfProg, header.Phnum)
+	for i := range progs {
+		reader.Seek(int64(header.Phoff)+int64(i)*int64(header.Phentsize), io.SeekStart)
+		if file.Class == elf.ELFCLASS64 {
+			var raw elf.Prog64
+			if err := binary.Read(reader, order, &raw); err != nil {
+				return header, nil, nil, err
+			}
+			progs[i] = elfProg{raw.Type, raw.Flags, raw.Off, raw.Vaddr, raw.Paddr, raw.Filesz, raw.Memsz, raw.Align}
+		} else {
+			var raw elf.Prog32
+			if err := binary.Read(reader, order, &raw); err != nil {
+				return header, nil, nil, err
+			}
+			progs[i] = elfProg{raw.Type, raw.Flags, uint64(raw.Off), uint64(raw.Vaddr), uint64(raw.Paddr),
+				uint64(raw.Filesz), uint64(raw.Memsz), uint64(raw.Align)}
+		}
+	}
+
+	return header, sections, progs, nil
+}
+
+func writeElfHeader(w io.Writer, h elfHeader, is64 bool, order binary.ByteOrder) error {
+	if is64 {
+		return binary.Write(w, order, elf.Header64{Ident: h.Ident, Type: h.Type, Machine: h.Machine, Version: h.Version,
+			Entry: h.Entry, Phoff: h.Phoff, Shoff: h.Shoff, Flags: h.Flags, Ehsize: h.Ehsize, Phentsize: h.Phentsize,
+			Phnum: h.Phnum, Shentsize: h.Shentsize, Shnum: h.Shnum, Shstrndx: h.Shstrndx})
+	}
+	return binary.Write(w, order, elf.Header32{Ident: h.Ident, Type: h.Type, Machine: h.Machine, Version: h.Version,
+		Entry: uint32(h.Entry), Phoff: uint32(h.Phoff), Shoff: uint32(h.Shoff), Flags: h.Flags, Ehsize: h.Ehsize,
+		Phentsize: h.Phentsize, Phnum: h.Phnum, Shentsize: h.Shentsize, Shnum: h.Shnum, Shstrndx: h.Shstrndx})
+}
+
+func writeElfSection(w io.Writer, s elfSection, is64 bool, order binary.ByteOrder) error {
+	if is64 {
+		return binary.Write(w, order, elf.Section64{Name: s.Name, Type: s.Type, Flags: s.Flags, Addr: s.Addr,
+			Off: s.Offset, Size: s.Size, Link: s.Link, Info: s.Info, Addralign: s.Addralign, Entsize: s.Entsize})
+	}
+	return binary.Write(w, order, elf.Section32{Name: s.Name, Type: s.Type, Flags: uint32(s.Flags), Addr: uint32(s.Addr),
+		Off: uint32(s.Offset), Size: uint32(s.Size), Link: s.Link, Info: s.Info, Addralign: uint32(s.Addralign),
+		Entsize: uint32(s.Entsize)})
+}
+
+func writeElfProg(w io.Writer, p elfProg, is64 bool, order binary.ByteOrder) error {
+	if is64 {
+		return binary.Write(w, order, elf.Prog64{Type: p.Type, Flags: p.Flags, Off: p.Off, Vaddr: p.Vaddr,
+			Paddr: p.Paddr, Filesz: p.Filesz, Memsz: p.Memsz, Align: p.Align})
+	}
+	return binary.Write(w, order, elf.Prog32{Type: p.Type, Flags: p.Flags, Off: uint32(p.Off), Vaddr: uint32(p.Vaddr),
+		Paddr: uint32(p.Paddr), Filesz: uint32(p.Filesz), Memsz: uint32(p.Memsz), Align: uint32(p.Align)})
+}
+
+// alignTo rounds offset up to the next multiple of align.
+func alignTo(offset, align uint64) uint64 {
+	if align <= 1 {
+		return offset
+	}
+	return (offset + align - 1) / align * align
+}
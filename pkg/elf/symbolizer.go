@@ -0,0 +1,190 @@
+package elf
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"fmt"
+	"sort"
+)
+
+// SymbolizedFrame is a function, source file and line that an address was compiled from.
+// File is empty and Line is zero if there is no line information for the address.
+type SymbolizedFrame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+}
+
+// Symbolizer resolves addresses in an ELF binary to the functions, source files and lines
+// they were compiled from, using the binary's DWARF debug information, including the
+// functions that were inlined at the address, or its symbol table if it has none.
+type Symbolizer struct {
+	HasDwarf bool
+
+	functions []functionRange
+	rows      []LineRow
+	files     []string
+	symbols   []elf.Symbol
+}
+
+// functionRange is an address range of a function that was not inlined.
+type functionRange struct {
+	low, high uint64
+	function  *DwarfFunction
+}
+
+// NewSymbolizer reads the DWARF debug information and symbol table of an ELF binary.
+//
+// Parameters:
+// - path: The path to the ELF binary.
+//
+// Returns:
+// - *Symbolizer: The symbolizer for the binary.
+// - error: An error if the binary or its DWARF information cannot be read.
+func NewSymbolizer(path string) (*Symbolizer, error) {
+	file, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ELF file: %w", err)
+	}
+	defer file.Close()
+
+	symbolizer := &Symbolizer{}
+
+	if data, err := file.DWARF(); err == nil {
+		symbolizer.HasDwarf = true
+		if err := symbolizer.readDwarf(data); err != nil {
+			return nil, fmt.Errorf("failed to read DWARF information from %s: %w", path, err)
+		}
+	}
+
+	symbolizer.symbols = FunctionSymbols(file)
+
+	return symbolizer, nil
+}
+
+// Symbolize returns the frames for an address, from the innermost inlined function out to
+// the function that contains it. Addresses are virtual addresses in the binary, which is
+// how the relative `pc` values in Android tombstones are given.
+//
+// Parameters:
+// - address: The address to symbolize.
+//
+// Returns:
+// - []SymbolizedFrame: The frames at the address, or nil if it is not in a known function.
+func (s *Symbolizer) Symbolize(address uint64) []SymbolizedFrame {
+	innermost := SymbolizedFrame{}
+	if row := s.findRow(address); row != nil {
+		innermost.File, innermost.Line, innermost.Column = s.files[row.File], row.Line, row.Column
+	}
+
+	function := s.findFunction(address)
+	if function == nil {
+		symbol := s.findSymbol(address)
+		if symbol == nil {
+			return nil
+		}
+		innermost.Function = symbol.Name
+		return []SymbolizedFrame{innermost}
+	}
+
+	// Follow the inlined calls that contain the address, from the outermost inwards
+	chain := []*DwarfFunction{function}
+	for scope := function; scope != nil; {
+		var next *DwarfFunction
+		for _, inlined := range scope.Inlined {
+			if inRanges(inlined.Ranges, address) {
+				next = inlined
+				chain = append(chain, next)
+				break
+			}
+		}
+		scope = next
+	}
+
+	frames := make([]SymbolizedFrame, len(chain))
+	frames[0] = innermost
+	for i := range chain {
+		scope := chain[len(chain)-1-i]
+		frames[i].Function = scope.Name
+		if i+1 < len(frames) {
+			// The caller is at the source location of the call to the inlined function
+			frames[i+1] = SymbolizedFrame{File: scope.CallFile, Line: scope.CallLine, Column: scope.CallColumn}
+		}
+	}
+	return frames
+}
+
+// findFunction returns the non-inlined function containing an address, or nil if there is none.
+func (s *Symbolizer) findFunction(address uint64) *DwarfFunction {
+	i := sort.Search(len(s.functions), func(i int) bool {
+		return s.functions[i].low > address
+	})
+	// Functions do not overlap, but the same range can be listed by more than one
+	// compilation unit, so only the closest few before the address need checking
+	for j := i - 1; j >= 0 && j >= i-8; j-- {
+		if address < s.functions[j].high {
+			return s.functions[j].function
+		}
+	}
+	return nil
+}
+
+// findRow returns the line table row containing an address, or nil if there is none.
+func (s *Symbolizer) findRow(address uint64) *LineRow {
+	i := sort.Search(len(s.rows), func(i int) bool {
+		return s.rows[i].Address+s.rows[i].Size > address
+	})
+	if i < len(s.rows) && s.rows[i].Address <= address {
+		return &s.rows[i]
+	}
+	return nil
+}
+
+// findSymbol returns the function symbol containing an address, or the closest symbol
+// before it if the symbol has no size, or nil if there is none.
+func (s *Symbolizer) findSymbol(address uint64) *elf.Symbol {
+	i := sort.Search(len(s.symbols), func(i int) bool {
+		return s.symbols[i].Value > address
+	})
+	if i == 0 {
+		return nil
+	}
+	symbol := &s.symbols[i-1]
+	if symbol.Size > 0 && address >= symbol.Value+symbol.Size {
+		return nil
+	}
+	return symbol
+}
+
+func inRanges(ranges [][2]uint64, address uint64) bool {
+	for _, r := range ranges {
+		if address >= r[0] && address < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// readDwarf records the line table and the functions read from the DWARF information.
+func (s *Symbolizer) readDwarf(data *dwarf.Data) error {
+	info, err := ReadDwarf(data)
+	if err != nil {
+		return err
+	}
+
+	s.rows, s.files = info.Rows, info.Files
+	for _, function := range info.Functions {
+		for _, r := range function.Ranges {
+			// Functions removed by the linker are left at address zero
+			if r[0] != 0 && r[1] > r[0] {
+				s.functions = append(s.functions, functionRange{low: r[0], high: r[1], function: function})
+			}
+		}
+	}
+
+	sort.Slice(s.functions, func(i, j int) bool {
+		return s.functions[i].low < s.functions[j].low
+	})
+	return nil
+}
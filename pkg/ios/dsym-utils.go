@@ -3,7 +3,6 @@ package ios
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -66,12 +65,8 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool, logg
 		}
 	}
 
-	// If we have found dSYMs, use dwarfdump to get the UUID etc for each dSYM
+	// If we have found dSYMs, read the UUID etc for each dSYM
 	if len(dsymLocations) > 0 {
-		if !isDwarfDumpInstalled() {
-			return nil, tempDir, fmt.Errorf("Unable to locate dwarfdump on this system.")
-		}
-
 		for _, dsymLocation := range dsymLocations {
 			fileInfo, err := os.Stat(dsymLocation)
 
@@ -83,6 +78,7 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool, logg
 			if fileInfo.Size() == 0 {
 				if ignoreEmptyDsym {
 					logger.Info(fmt.Sprintf("%s is empty, skipping", dsymLocation))
+					log.ReportFile(logger, log.FileResult{File: dsymLocation, Status: log.FileSkipped, Reason: "empty dSYM"})
 					continue
 				} else {
 					return nil, tempDir, fmt.Errorf("%s is empty", dsymLocation)
@@ -98,13 +94,13 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool, logg
 				if strings.Contains(err.Error(), "not a directory") {
 					fileName := filepath.Base(dsymLocation)
 					dsymLocation = filepath.Dir(dsymLocation)
-					dwarfInfo = append(dwarfInfo, getDwarfFileInfo(dsymLocation, fileName)...)
+					dwarfInfo = append(dwarfInfo, getDwarfFileInfo(dsymLocation, fileName, logger)...)
 				}
 			}
 
 			for _, file := range filesFound {
 				// Extract DWARF info
-				info := getDwarfFileInfo(dwarfLocation, file.Name())
+				info := getDwarfFileInfo(dwarfLocation, file.Name(), logger)
 				if len(info) > 0 {
 					dwarfInfo = append(dwarfInfo, info...)
 				}
@@ -113,6 +109,7 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool, logg
 			if len(dwarfInfo) == 0 {
 				if ignoreMissingDwarf {
 					logger.Info(fmt.Sprintf("%s does not contain valid DWARF information, skipping", dsymLocation))
+					log.ReportFile(logger, log.FileResult{File: dsymLocation, Status: log.FileSkipped, Reason: "missing DWARF information"})
 				} else {
 					return nil, tempDir, fmt.Errorf("%s does not contain valid DWARF information", dsymLocation)
 				}
@@ -123,41 +120,37 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool, logg
 	return dwarfInfo, tempDir, nil
 }
 
-// isDwarfDumpInstalled checks if the `dwarfdump` utility is available on the system.
-//
-// Returns:
-// - `true` if the `dwarfdump` command is found in the system's executable path.
-// - `false` otherwise.
-func isDwarfDumpInstalled() bool {
-	return utils.LocationOf(utils.DWARFDUMP) != ""
-}
-
-// getDwarfFileInfo retrieves DWARF file information from the output of the `dwarfdump` utility.
+// getDwarfFileInfo retrieves DWARF file information by reading the Mach-O load commands
+// and sections of the file directly. Slices without DWARF debug information are skipped.
 //
 // Parameters:
 // - path: The directory path containing the DWARF file.
 // - fileName: The name of the DWARF file to be analyzed.
+// - logger: Logger instance for debug messages.
 //
 // Returns:
 // - A slice of DwarfInfo structs containing extracted DWARF information.
-func getDwarfFileInfo(path, fileName string) []*DwarfInfo {
+func getDwarfFileInfo(path, fileName string, logger log.Logger) []*DwarfInfo {
 	var dwarfInfo []*DwarfInfo
-	cmd := exec.Command(utils.DWARFDUMP, "-u", fileName)
-	cmd.Dir = path
-
-	output, _ := cmd.Output()
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		if strings.Contains(line, "UUID: ") {
-			parts := strings.Fields(strings.ReplaceAll(strings.ReplaceAll(line, "(", ""), ")", ""))
-			if len(parts) >= 4 {
-				dwarfInfo = append(dwarfInfo, &DwarfInfo{
-					UUID:     parts[1],
-					Arch:     parts[2],
-					Name:     strings.Join(parts[3:], " "),
-					Location: path,
-				})
-			}
+
+	slices, err := GetMachoSlices(filepath.Join(path, fileName))
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Unable to read %s: %s", fileName, err))
+		return nil
+	}
+
+	for _, slice := range slices {
+		if slice.UUID == "" || !slice.HasDwarf {
+			logger.Debug(fmt.Sprintf("Skipping %s slice of %s - no UUID or DWARF information", slice.Arch, fileName))
+			continue
 		}
+
+		dwarfInfo = append(dwarfInfo, &DwarfInfo{
+			UUID:     slice.UUID,
+			Arch:     slice.Arch,
+			Name:     fileName,
+			Location: path,
+		})
 	}
 	return dwarfInfo
 }
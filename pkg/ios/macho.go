@@ -0,0 +1,142 @@
+package ios
+
+import (
+	"debug/macho"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// loadCmdUuid is the LC_UUID load command, which debug/macho does not decode.
+const loadCmdUuid macho.LoadCmd = 0x1b
+
+// cpuSubtypeMask removes the capability bits from a Mach-O CPU subtype.
+const cpuSubtypeMask = 0x00ffffff
+
+// cpuArm64_32 is the CPU type of arm64_32 (watchOS) slices.
+const cpuArm64_32 macho.Cpu = 0x0200000c
+
+// MachoSlice describes one architecture slice of a Mach-O file.
+type MachoSlice struct {
+	UUID     string
+	Arch     string
+	HasDwarf bool
+}
+
+// GetMachoSlices reads the UUID, architecture and presence of DWARF debug information
+// for each slice of a Mach-O file. Both thin and fat (universal) binaries are supported.
+//
+// Parameters:
+// - path: The path to the Mach-O file.
+//
+// Returns:
+// - A slice of MachoSlice structs, one for each architecture in the file.
+// - An error if the file cannot be opened or is not a Mach-O file.
+func GetMachoSlices(path string) ([]*MachoSlice, error) {
+	fatFile, err := macho.OpenFat(path)
+	if err == nil {
+		defer fatFile.Close()
+
+		var slices []*MachoSlice
+		for _, arch := range fatFile.Arches {
+			slices = append(slices, getMachoSlice(arch.File))
+		}
+		return slices, nil
+	}
+
+	if !errors.Is(err, macho.ErrNotFat) {
+		return nil, fmt.Errorf("failed to open Mach-O file %s: %w", path, err)
+	}
+
+	file, err := macho.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Mach-O file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return []*MachoSlice{getMachoSlice(file)}, nil
+}
+
+// GetMachoUuid returns the UUID of the slice of a Mach-O file that matches the given architecture.
+//
+// Parameters:
+// - path: The path to the Mach-O file.
+// - arch: The architecture name, e.g. "arm64".
+//
+// Returns:
+// - The UUID of the matching slice.
+// - An error if the file cannot be read or has no slice with a UUID for the architecture.
+func GetMachoUuid(path string, arch string) (string, error) {
+	slices, err := GetMachoSlices(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, slice := range slices {
+		if slice.Arch == arch && slice.UUID != "" {
+			return slice.UUID, nil
+		}
+	}
+
+	return "", fmt.Errorf("no %s UUID found in %s", arch, path)
+}
+
+// getMachoSlice extracts the details of a single thin Mach-O file.
+func getMachoSlice(file *macho.File) *MachoSlice {
+	return &MachoSlice{
+		UUID:     getMachoUuid(file),
+		Arch:     getMachoArch(file.Cpu, file.SubCpu),
+		HasDwarf: file.Section("__debug_info") != nil,
+	}
+}
+
+// getMachoUuid returns the value of the LC_UUID load command formatted the same way
+// as dwarfdump, e.g. "E30C1BE5-DEB6-373C-98B4-52D827B7FF0D", or an empty string if the
+// file has no UUID.
+func getMachoUuid(file *macho.File) string {
+	for _, load := range file.Loads {
+		raw := load.Raw()
+		if len(raw) < 24 || macho.LoadCmd(file.ByteOrder.Uint32(raw[0:4])) != loadCmdUuid {
+			continue
+		}
+
+		uuid := raw[8:24]
+		return strings.ToUpper(fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]))
+	}
+	return ""
+}
+
+// getMachoArch converts a Mach-O CPU type and subtype into the architecture name used by
+// Xcode and dwarfdump.
+func getMachoArch(cpu macho.Cpu, subCpu uint32) string {
+	subCpu &= cpuSubtypeMask
+
+	switch cpu {
+	case macho.CpuArm64:
+		if subCpu == 2 {
+			return "arm64e"
+		}
+		return "arm64"
+	case cpuArm64_32:
+		return "arm64_32"
+	case macho.CpuArm:
+		switch subCpu {
+		case 11:
+			return "armv7s"
+		case 12:
+			return "armv7k"
+		case 9:
+			return "armv7"
+		}
+		return "arm"
+	case macho.CpuAmd64:
+		if subCpu == 8 {
+			return "x86_64h"
+		}
+		return "x86_64"
+	case macho.Cpu386:
+		return "i386"
+	}
+
+	return strings.TrimPrefix(strings.ToLower(cpu.String()), "cpu")
+}
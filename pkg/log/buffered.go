@@ -0,0 +1,80 @@
+package log
+
+import "sync"
+
+type bufferedEntry struct {
+	level  string
+	msg    string
+	result FileResult
+}
+
+// BufferedLogger records log messages in memory so that output produced by
+// concurrent work can be replayed in a deterministic order.
+type BufferedLogger struct {
+	mu      sync.Mutex
+	entries []bufferedEntry
+}
+
+func NewBufferedLogger() *BufferedLogger {
+	return &BufferedLogger{}
+}
+
+func (bl *BufferedLogger) record(level string, msg string) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bl.entries = append(bl.entries, bufferedEntry{level: level, msg: msg})
+}
+
+func (bl *BufferedLogger) Debug(msg string) {
+	bl.record("debug", msg)
+}
+
+func (bl *BufferedLogger) Info(msg string) {
+	bl.record("info", msg)
+}
+
+func (bl *BufferedLogger) Warn(msg string) {
+	bl.record("warn", msg)
+}
+
+func (bl *BufferedLogger) Error(msg string) {
+	bl.record("error", msg)
+}
+
+// Fatal is recorded like any other message; the exit happens when the entry
+// is replayed against the target logger in Flush.
+func (bl *BufferedLogger) Fatal(msg string) {
+	bl.record("fatal", msg)
+}
+
+func (bl *BufferedLogger) ReportFile(result FileResult) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bl.entries = append(bl.entries, bufferedEntry{level: "report", result: result})
+}
+
+// Flush replays every recorded message and file result against the target logger,
+// in the order they were recorded, and clears the buffer.
+func (bl *BufferedLogger) Flush(target Logger) {
+	bl.mu.Lock()
+	entries := bl.entries
+	bl.entries = nil
+	bl.mu.Unlock()
+
+	for _, entry := range entries {
+		switch entry.level {
+		case "debug":
+			target.Debug(entry.msg)
+		case "info":
+			target.Info(entry.msg)
+		case "warn":
+			target.Warn(entry.msg)
+		case "error":
+			target.Error(entry.msg)
+		case "fatal":
+			target.Fatal(entry.msg)
+		case "report":
+			ReportFile(target, entry.result)
+		}
+	}
+}
@@ -20,7 +20,10 @@ func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	output := ""
 
 	// Check if output is a TTY
-	isTerminal := isatty.IsTerminal(os.Stdout.Fd())
+	isTerminal := false
+	if file, ok := entry.Logger.Out.(*os.File); ok {
+		isTerminal = isatty.IsTerminal(file.Fd())
+	}
 
 	output += "["
 
@@ -66,23 +69,27 @@ func NewLogrusLogger(logLevel string) *LogrusLogger {
 	return &LogrusLogger{logger: logger}
 }
 
-func (l *LogrusLogger) Debug(msg string) {
+// SetOutput sets where log messages are written to, which is stdout by default.
+func (l *LogrusLogger) SetOutput(out *os.File) {
+	l.logger.Out = out
+}
 
-	l.logger.WithFields(nil).Debug(msg)
+func (l *LogrusLogger) Debug(msg string) {
+	withStatusHidden(func() { l.logger.WithFields(nil).Debug(msg) })
 }
 
 func (l *LogrusLogger) Info(msg string) {
-	l.logger.WithFields(nil).Info(msg)
+	withStatusHidden(func() { l.logger.WithFields(nil).Info(msg) })
 }
 
 func (l *LogrusLogger) Warn(msg string) {
-	l.logger.WithFields(nil).Warn(msg)
+	withStatusHidden(func() { l.logger.WithFields(nil).Warn(msg) })
 }
 
 func (l *LogrusLogger) Error(msg string) {
-	l.logger.WithFields(nil).Error(msg)
+	withStatusHidden(func() { l.logger.WithFields(nil).Error(msg) })
 }
 
 func (l *LogrusLogger) Fatal(msg string) {
-	l.logger.WithFields(nil).Fatal(msg)
+	withStatusHidden(func() { l.logger.WithFields(nil).Fatal(msg) })
 }
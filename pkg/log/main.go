@@ -1,31 +1,111 @@
 package log
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Output modes supported by the logger wrapper.
+const (
+	OutputText = "text"
+	OutputJson = "json"
+)
+
 type LoggerWrapper struct {
-	logger Logger
+	logger  Logger
+	output  string
+	command string
+
+	mu      sync.Mutex
+	results []FileResult
 }
 
-func NewLoggerWrapper(logLevel string) *LoggerWrapper {
+// NewLoggerWrapper creates the logger used by the CLI. Secrets are masked in every message
+// logged, unless they are shown with SetShowSecrets.
+//
+// In the JSON output mode, log messages are written to stderr so that stdout only
+// contains the summary written by WriteSummary.
+func NewLoggerWrapper(logLevel string, output string) *LoggerWrapper {
 	logger := NewLogrusLogger(logLevel)
 
-	return &LoggerWrapper{logger: logger}
+	if output == OutputJson {
+		logger.SetOutput(os.Stderr)
+	}
+
+	return &LoggerWrapper{logger: logger, output: output}
+}
+
+// SetCommand sets the name of the command included in the summary.
+func (lw *LoggerWrapper) SetCommand(command string) {
+	lw.command = command
+}
+
+// SetOutput sets where log messages are written, for commands that write their results to stdout.
+func (lw *LoggerWrapper) SetOutput(out *os.File) {
+	if logger, ok := lw.logger.(*LogrusLogger); ok {
+		logger.SetOutput(out)
+	}
 }
 
 func (lw *LoggerWrapper) Debug(msg string) {
-	lw.logger.Debug(msg)
+	lw.logger.Debug(Redact(msg))
 }
 
 func (lw *LoggerWrapper) Info(msg string) {
-	lw.logger.Info(msg)
+	lw.logger.Info(Redact(msg))
 }
 
 func (lw *LoggerWrapper) Warn(msg string) {
-	lw.logger.Warn(msg)
+	lw.logger.Warn(Redact(msg))
 }
 
 func (lw *LoggerWrapper) Error(msg string) {
-	lw.logger.Error(msg)
+	lw.logger.Error(Redact(msg))
 }
 
+// Fatal logs the message and exits. In the JSON output mode the summary is written first,
+// recording the message as the command's error.
 func (lw *LoggerWrapper) Fatal(msg string) {
-	lw.logger.Fatal(msg)
+	lw.WriteSummary(msg)
+	lw.logger.Fatal(Redact(msg))
+}
+
+func (lw *LoggerWrapper) ReportFile(result FileResult) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.results = append(lw.results, result)
+}
+
+// WriteSummary writes the JSON summary of the command to stdout, if the JSON output mode
+// is enabled. errMsg is the error that caused the command to fail, or empty on success.
+func (lw *LoggerWrapper) WriteSummary(errMsg string) {
+	if lw.output != OutputJson {
+		return
+	}
+
+	lw.mu.Lock()
+	summary := Summary{
+		Command: lw.command,
+		Success: errMsg == "",
+		Error:   errMsg,
+		Files:   lw.results,
+	}
+	if summary.Files == nil {
+		summary.Files = []FileResult{}
+	}
+	lw.mu.Unlock()
+
+	// Secrets are masked in the encoded summary, such as API keys in endpoints and errors
+	var encoded strings.Builder
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		lw.logger.Error(fmt.Sprintf("failed to write JSON summary: %s", err.Error()))
+		return
+	}
+	os.Stdout.WriteString(Redact(encoded.String()))
 }
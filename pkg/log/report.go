@@ -0,0 +1,44 @@
+package log
+
+// File statuses used in a FileResult.
+const (
+	FileUploaded = "uploaded"
+	FileSkipped  = "skipped"
+	FileFailed   = "failed"
+	FileDryRun   = "dry-run"
+	FilePackaged = "packaged"
+	FileValid    = "valid"
+	FileInvalid  = "invalid"
+)
+
+// FileResult describes what happened to a single file during a command, for the
+// machine-readable summary written by the JSON output mode.
+type FileResult struct {
+	File        string            `json:"file"`
+	Status      string            `json:"status"`
+	Reason      string            `json:"reason,omitempty"`
+	Endpoint    string            `json:"endpoint,omitempty"`
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+	Warnings    []string          `json:"warnings,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Summary is the machine-readable summary of a command written by the JSON output mode.
+type Summary struct {
+	Command string       `json:"command"`
+	Success bool         `json:"success"`
+	Error   string       `json:"error,omitempty"`
+	Files   []FileResult `json:"files"`
+}
+
+// Reporter is implemented by loggers that collect the results of the files processed by a command.
+type Reporter interface {
+	ReportFile(result FileResult)
+}
+
+// ReportFile records the result for a file if the logger collects results, and does nothing otherwise.
+func ReportFile(logger Logger, result FileResult) {
+	if reporter, ok := logger.(Reporter); ok {
+		reporter.ReportFile(result)
+	}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// statusLine is a line of progress drawn on stderr below the log messages, when stderr is a
+// terminal. It is cleared before each log message is written and drawn again afterwards, so
+// that it always stays at the bottom of the output.
+type statusLine struct {
+	mu   sync.Mutex
+	out  *os.File
+	text string
+}
+
+var status = &statusLine{out: os.Stderr}
+
+// StatusSupported reports whether a status line can be drawn, which requires stderr to be
+// a terminal.
+func StatusSupported() bool {
+	return isatty.IsTerminal(status.out.Fd()) || isatty.IsCygwinTerminal(status.out.Fd())
+}
+
+// SetStatus draws the status line, replacing any that is already shown.
+//
+// Parameters:
+//   - text: The text of the status line, which should fit on one line of the terminal.
+func SetStatus(text string) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+
+	status.text = text
+	status.out.WriteString("\r\x1b[K" + text)
+}
+
+// ClearStatus removes the status line.
+func ClearStatus() {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+
+	if status.text != "" {
+		status.out.WriteString("\r\x1b[K")
+		status.text = ""
+	}
+}
+
+// withStatusHidden clears the status line while a log message is written, then draws it
+// again underneath.
+func withStatusHidden(write func()) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+
+	if status.text == "" {
+		write()
+		return
+	}
+
+	status.out.WriteString("\r\x1b[K")
+	write()
+	status.out.WriteString(status.text)
+}
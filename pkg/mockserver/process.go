@@ -0,0 +1,66 @@
+package mockserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+)
+
+// ProcessMockServer runs the mock server until it is interrupted.
+//
+// Parameters:
+// - globalOptions: CLI options including the address to listen on and the scripted responses.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if the options are invalid or the server cannot listen on the address.
+func ProcessMockServer(globalOptions options.CLI, logger log.Logger) error {
+	serverOptions := globalOptions.MockServer
+
+	responses, err := ParseResponses(serverOptions.Respond)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(serverOptions.OutputDir, 0755); err != nil {
+		return fmt.Errorf("creating output directory %s: %w", serverOptions.OutputDir, err)
+	}
+
+	listener, err := net.Listen("tcp", serverOptions.Address)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           New(serverOptions.OutputDir, responses, serverOptions.RetryAfter, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info(fmt.Sprintf("Mock server listening on http://%s, recording requests in %s", listener.Addr(), serverOptions.OutputDir))
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info("Mock server stopped")
+	return nil
+}
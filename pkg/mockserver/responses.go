@@ -0,0 +1,65 @@
+package mockserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Responses is a script of the status codes to return for each endpoint. Each request to
+// an endpoint uses the next status code in its script, and once the script has been
+// used up the endpoint responds with 200 OK.
+type Responses struct {
+	mu      sync.Mutex
+	scripts map[string][]int
+}
+
+// ParseResponses parses scripted responses of the form `<path>=<status>[,<status>...]`,
+// for example `/dsym=503,503,200` or `/proguard=409`.
+//
+// Parameters:
+//   - specs: The scripted responses, one per endpoint.
+//
+// Returns:
+//   - *Responses: The parsed script.
+//   - error: An error if a script is not in the expected form or has an invalid status code.
+func ParseResponses(specs []string) (*Responses, error) {
+	responses := &Responses{scripts: map[string][]int{}}
+
+	for _, spec := range specs {
+		path, statuses, ok := strings.Cut(spec, "=")
+		if !ok || !strings.HasPrefix(path, "/") || statuses == "" {
+			return nil, fmt.Errorf("invalid response %q, expected <path>=<status>[,<status>...]", spec)
+		}
+
+		for _, value := range strings.Split(statuses, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || status < 100 || status > 599 {
+				return nil, fmt.Errorf("invalid status code %q in response %q", value, spec)
+			}
+			responses.scripts[path] = append(responses.scripts[path], status)
+		}
+	}
+
+	return responses, nil
+}
+
+// next returns the status code for the next request to the given path.
+func (r *Responses) next(path string) int {
+	if r == nil {
+		return http.StatusOK
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	script := r.scripts[path]
+	if len(script) == 0 {
+		return http.StatusOK
+	}
+
+	r.scripts[path] = script[1:]
+	return script[0]
+}
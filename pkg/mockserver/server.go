@@ -0,0 +1,265 @@
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+// Endpoints are the upload endpoints implemented by the mock server. Builds are posted
+// as JSON to the root of the build API, which is also accepted at /builds.
+var Endpoints = []string{
+	"/",
+	"/dsym",
+	"/proguard",
+	"/ndk-symbol",
+	"/linux",
+	"/dart-symbol",
+	"/breakpad-symbol",
+	"/sourcemap",
+	"/react-native-source-map",
+	"/unity-line-mappings",
+	"/builds",
+}
+
+// The largest multipart field value or JSON body that is recorded in request.json.
+const maxRecordedValue = 1 << 20
+
+// RecordedFile describes a file received in a multipart upload.
+type RecordedFile struct {
+	Field    string `json:"field"`
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Sha256   string `json:"sha256"`
+	Path     string `json:"path"`
+}
+
+// RecordedRequest is written to request.json for every request the server receives.
+type RecordedRequest struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query,omitempty"`
+	Headers map[string][]string `json:"headers"`
+	Fields  map[string][]string `json:"fields,omitempty"`
+	Files   []RecordedFile      `json:"files,omitempty"`
+	Body    json.RawMessage     `json:"body,omitempty"`
+	Status  int                 `json:"status"`
+}
+
+// Server is a mock of the BugSnag upload and build APIs that records every request it
+// receives to disk and responds with scripted status codes.
+type Server struct {
+	outputDir  string
+	responses  *Responses
+	retryAfter int
+	logger     log.Logger
+
+	mu      sync.Mutex
+	counter int
+}
+
+// New creates a mock server.
+//
+// Parameters:
+//   - outputDir: The directory that each request is recorded in.
+//   - responses: The scripted status codes to respond with, or nil to always respond with 200 OK.
+//   - retryAfter: The number of seconds to send in the Retry-After header of 429 and 503 responses, or 0 to omit it.
+//   - logger: The logger used to report each request.
+//
+// Returns:
+//   - *Server: The mock server.
+func New(outputDir string, responses *Responses, retryAfter int, logger log.Logger) *Server {
+	return &Server{
+		outputDir:  outputDir,
+		responses:  responses,
+		retryAfter: retryAfter,
+		logger:     logger,
+	}
+}
+
+// ServeHTTP records the request and responds with the next scripted status for its path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := r.URL.Path
+	if endpoint != "/" {
+		endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+
+	requestDir := filepath.Join(s.outputDir, s.nextRequestName(endpoint))
+	record := RecordedRequest{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.Query(),
+		Headers: r.Header,
+	}
+
+	status := http.StatusNotFound
+	if slices.Contains(Endpoints, endpoint) {
+		if r.Method != http.MethodPost {
+			status = http.StatusMethodNotAllowed
+		} else if err := s.readBody(r, requestDir, &record); err != nil {
+			s.logger.Warn(fmt.Sprintf("Unable to read request to %s: %s", r.URL.Path, err))
+			status = http.StatusBadRequest
+		} else {
+			status = s.responses.next(endpoint)
+		}
+	}
+	record.Status = status
+
+	if err := writeRecord(requestDir, record); err != nil {
+		s.logger.Warn(fmt.Sprintf("Unable to record request to %s: %s", r.URL.Path, err))
+	}
+
+	s.logger.Info(fmt.Sprintf("%s %s -> %d (%d fields, %d files, recorded in %s)", r.Method, r.URL.Path, status, len(record.Fields), len(record.Files), requestDir))
+	s.respond(w, status)
+}
+
+// nextRequestName returns a unique, ordered directory name for a request to endpoint.
+func (s *Server) nextRequestName(endpoint string) string {
+	s.mu.Lock()
+	s.counter++
+	counter := s.counter
+	s.mu.Unlock()
+
+	name := strings.ReplaceAll(strings.Trim(endpoint, "/"), "/", "-")
+	if name == "" {
+		name = "root"
+	}
+	return fmt.Sprintf("%04d-%s", counter, name)
+}
+
+// readBody records the multipart fields and files, or JSON body, of a request.
+func (s *Server) readBody(r *http.Request, requestDir string, record *RecordedRequest) error {
+	if err := os.MkdirAll(requestDir, 0755); err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		return readMultipart(r, requestDir, record)
+	case "application/json":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		if !json.Valid(body) {
+			return fmt.Errorf("invalid JSON body")
+		}
+		if len(body) <= maxRecordedValue {
+			record.Body = body
+		}
+		return os.WriteFile(filepath.Join(requestDir, "body.json"), body, 0644)
+	default:
+		return fmt.Errorf("unsupported content type %q", r.Header.Get("Content-Type"))
+	}
+}
+
+// readMultipart saves each file in a multipart request to requestDir/files/<field>/<name>,
+// and records the value of each other field.
+func readMultipart(r *http.Request, requestDir string, record *RecordedRequest) error {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	record.Fields = map[string][]string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxRecordedValue))
+			if err != nil {
+				return err
+			}
+			record.Fields[part.FormName()] = append(record.Fields[part.FormName()], string(value))
+			continue
+		}
+
+		file, err := saveFile(part, requestDir)
+		if err != nil {
+			return err
+		}
+		record.Files = append(record.Files, file)
+	}
+
+	return nil
+}
+
+// saveFile writes a multipart file to disk and returns its details.
+func saveFile(part *multipart.Part, requestDir string) (RecordedFile, error) {
+	file := RecordedFile{
+		Field:    part.FormName(),
+		FileName: part.FileName(),
+	}
+
+	// Only the base names are used so that a request cannot write outside of requestDir
+	dir := filepath.Join(requestDir, "files", filepath.Base(filepath.Clean("/"+file.Field)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return file, err
+	}
+	file.Path = filepath.Join(dir, filepath.Base(filepath.Clean("/"+file.FileName)))
+
+	out, err := os.Create(file.Path)
+	if err != nil {
+		return file, err
+	}
+	defer out.Close()
+
+	hash := sha256.New()
+	file.Size, err = io.Copy(io.MultiWriter(out, hash), part)
+	if err != nil {
+		return file, err
+	}
+	file.Sha256 = hex.EncodeToString(hash.Sum(nil))
+
+	return file, out.Close()
+}
+
+// writeRecord writes request.json to requestDir.
+func writeRecord(requestDir string, record RecordedRequest) error {
+	if err := os.MkdirAll(requestDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(requestDir, "request.json"), data, 0644)
+}
+
+// respond writes a JSON response with the given status, in the form the BugSnag APIs use.
+func (s *Server) respond(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.retryAfter > 0 && (status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable) {
+		w.Header().Set("Retry-After", fmt.Sprint(s.retryAfter))
+	}
+	w.WriteHeader(status)
+
+	if status >= 200 && status < 300 {
+		_, _ = w.Write([]byte("{}\n"))
+		return
+	}
+
+	body, _ := json.Marshal(map[string]string{"error": http.StatusText(status)})
+	_, _ = w.Write(append(body, '\n'))
+}
@@ -27,11 +27,13 @@ type ReactNativeAndroid struct {
 }
 
 type ReactNativeShared struct {
-	Bundle       string `help:"The path to the bundled JavaScript file to upload" type:"path"`
-	CodeBundleId string `help:"A unique identifier for the JavaScript bundle"`
-	Dev          bool   `help:"Indicates whether this is a debug or release build"`
-	SourceMap    string `help:"The path to the source map file to upload" type:"path"`
-	VersionName  string `help:"The version of the application"`
+	Bundle           string   `help:"The path to the bundled JavaScript file to upload" type:"path"`
+	CodeBundleId     string   `help:"A unique identifier for the JavaScript bundle"`
+	Dev              bool     `help:"Indicates whether this is a debug or release build"`
+	SourceMap        string   `help:"The path to the source map file to upload" type:"path"`
+	VersionName      string   `help:"The version of the application"`
+	FlattenIndexMaps bool     `help:"Convert index (sectioned) source maps into regular source maps before uploading"`
+	ComposeSourceMap []string `help:"The source map of an earlier build step to compose with the source map before uploading, e.g. from TypeScript before minification. Repeat for each earlier step, latest first" type:"path" sep:"none"`
 }
 
 type ReactNativeIosSpecific struct {
@@ -52,15 +54,17 @@ type ReactNativeIos struct {
 }
 
 type ReactNativeSourcemaps struct {
-	Path          utils.Path     `arg:"" name:"path" help:"The path to the root of the React Native project to upload files from" type:"path" default:"."`
-	ProjectRoot   string         `help:"The path to strip from the beginning of source file names referenced in stacktraces on the BugSnag dashboard" type:"path"`
-	VersionName   string         `help:"The version of the application"`
-	VersionCode   string         `help:"The version code of this build of the application" xor:"version-code,bundle-version"`
-	BundleVersion string         `help:"The bundle version of this build of the application (Apple platforms only)" xor:"version-code,bundle-version"`
-	CodeBundleId  string         `help:"A unique identifier for the JavaScript bundle"`
-	Dev           bool           `help:"Indicates whether this is a debug or release build"`
-	Overwrite     bool           `help:"Whether to ignore and overwrite existing uploads with same identifier, rather than failing if a matching file exists"`
-	SourceMap     string         `help:"The path to the source map file to upload" type:"path"`
-	Bundle        string         `help:"The path to the bundled JavaScript file to upload" type:"path"`
-	Platform      utils.Platform `help:"The platform of the React Native build"`
+	Path             utils.Path     `arg:"" name:"path" help:"The path to the root of the React Native project to upload files from" type:"path" default:"."`
+	ProjectRoot      string         `help:"The path to strip from the beginning of source file names referenced in stacktraces on the BugSnag dashboard" type:"path"`
+	VersionName      string         `help:"The version of the application"`
+	VersionCode      string         `help:"The version code of this build of the application" xor:"version-code,bundle-version"`
+	BundleVersion    string         `help:"The bundle version of this build of the application (Apple platforms only)" xor:"version-code,bundle-version"`
+	CodeBundleId     string         `help:"A unique identifier for the JavaScript bundle"`
+	Dev              bool           `help:"Indicates whether this is a debug or release build"`
+	Overwrite        bool           `help:"Whether to ignore and overwrite existing uploads with same identifier, rather than failing if a matching file exists"`
+	SourceMap        string         `help:"The path to the source map file to upload" type:"path"`
+	Bundle           string         `help:"The path to the bundled JavaScript file to upload" type:"path"`
+	Platform         utils.Platform `help:"The platform of the React Native build"`
+	FlattenIndexMaps bool           `help:"Convert index (sectioned) source maps into regular source maps before uploading"`
+	ComposeSourceMap []string       `help:"The source map of an earlier build step to compose with the source map before uploading, e.g. from TypeScript before minification. Repeat for each earlier step, latest first" type:"path" sep:"none"`
 }
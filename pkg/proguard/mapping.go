@@ -0,0 +1,360 @@
+package proguard
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// R8 metadata IDs, see https://r8.googlesource.com/r8/+/refs/heads/main/doc/retrace.md
+const (
+	metadataSourceFile  = "sourceFile"
+	metadataSynthesized = "com.android.tools.r8.synthesized"
+)
+
+var (
+	classRe  = regexp.MustCompile(`^(\S+) -> (\S+):$`)
+	methodRe = regexp.MustCompile(`^(?:(\d+):(\d+):)?(\S+) (?:([^\s(]+)\.)?([^\s.(]+)\(([^)]*)\)(?::(\d+)(?::(\d+))?)? -> (\S+)$`)
+	fieldRe  = regexp.MustCompile(`^(\S+) (\S+) -> (\S+)$`)
+)
+
+// Mapping is a parsed Proguard or R8 mapping file.
+type Mapping struct {
+	// Classes keyed by their obfuscated name
+	classes map[string]*Class
+	// Classes keyed by their original name
+	originalClasses map[string]*Class
+}
+
+// Class is the mapping of a single class.
+type Class struct {
+	Original    string
+	Obfuscated  string
+	SourceFile  string
+	Synthesized bool
+	Fields      map[string]string
+	Methods     []*Method
+}
+
+// Method is the mapping of a single method, or of a method inlined into it. Methods that
+// were inlined are listed in the mapping file on consecutive lines with the same obfuscated
+// name and line range, from the innermost inlined method to the outer method.
+type Method struct {
+	ReturnType string
+	// OriginalClass is set if the method was inlined from another class
+	OriginalClass string
+	Original      string
+	Arguments     string
+	Obfuscated    string
+	Synthesized   bool
+
+	// The obfuscated line range, which are zero if the method has no line information
+	StartLine int
+	EndLine   int
+	// The original line range, which are zero if it is the same as the obfuscated range
+	OriginalStartLine int
+	OriginalEndLine   int
+
+	// The index of the group of inlined methods that the method is part of
+	group int
+}
+
+// Frame is a deobfuscated stack frame.
+type Frame struct {
+	Class  string
+	Method string
+	File   string
+	Line   int
+}
+
+// ParseMappingFile parses a Proguard or R8 mapping file.
+//
+// Parameters:
+// - path: The path to the mapping file.
+//
+// Returns:
+// - *Mapping: The parsed mapping.
+// - error: An error if the file cannot be read or is not a valid mapping file.
+func ParseMappingFile(path string) (*Mapping, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open mapping file: %w", err)
+	}
+	defer file.Close()
+
+	mapping, err := ParseMapping(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// ParseMapping parses the contents of a Proguard or R8 mapping file, including the R8
+// `# {"id":...}` metadata that gives the source file of each class and marks synthesized
+// classes and methods.
+//
+// Parameters:
+// - r: The contents of the mapping file.
+//
+// Returns:
+// - *Mapping: The parsed mapping.
+// - error: An error if a line is not in the mapping format.
+func ParseMapping(r io.Reader) (*Mapping, error) {
+	mapping := &Mapping{classes: map[string]*Class{}, originalClasses: map[string]*Class{}}
+
+	var class *Class
+	var lastMethod *Method
+	group := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		isMember := trimmed != line
+
+		switch {
+		case trimmed == "":
+			continue
+
+		case strings.HasPrefix(trimmed, "#"):
+			metadata := parseMetadata(trimmed)
+			if metadata == nil || class == nil {
+				continue
+			}
+			// Indented metadata applies to the member above it, and otherwise to the class
+			if isMember {
+				if lastMethod != nil && metadata.Id == metadataSynthesized {
+					lastMethod.Synthesized = true
+				}
+				continue
+			}
+			switch metadata.Id {
+			case metadataSourceFile:
+				class.SourceFile = metadata.FileName
+			case metadataSynthesized:
+				class.Synthesized = true
+			}
+
+		case !isMember:
+			matches := classRe.FindStringSubmatch(trimmed)
+			if matches == nil {
+				return nil, fmt.Errorf("invalid class mapping on line %d: %s", lineNumber, trimmed)
+			}
+			class = &Class{Original: matches[1], Obfuscated: matches[2], Fields: map[string]string{}}
+			mapping.classes[class.Obfuscated] = class
+			mapping.originalClasses[class.Original] = class
+			lastMethod = nil
+
+		case class == nil:
+			return nil, fmt.Errorf("member mapping outside of a class on line %d", lineNumber)
+
+		default:
+			if matches := methodRe.FindStringSubmatch(trimmed); matches != nil {
+				method := &Method{
+					StartLine:         atoi(matches[1]),
+					EndLine:           atoi(matches[2]),
+					ReturnType:        matches[3],
+					OriginalClass:     matches[4],
+					Original:          matches[5],
+					Arguments:         matches[6],
+					OriginalStartLine: atoi(matches[7]),
+					OriginalEndLine:   atoi(matches[8]),
+					Obfuscated:        matches[9],
+				}
+				if matches[7] != "" && matches[8] == "" {
+					method.OriginalEndLine = method.OriginalStartLine
+				}
+
+				// Inlined methods share the obfuscated name and line range of the method they were inlined into
+				if lastMethod == nil || method.StartLine == 0 || method.Obfuscated != lastMethod.Obfuscated ||
+					method.StartLine != lastMethod.StartLine || method.EndLine != lastMethod.EndLine {
+					group++
+				}
+				method.group = group
+
+				class.Methods = append(class.Methods, method)
+				lastMethod = method
+				continue
+			}
+
+			matches := fieldRe.FindStringSubmatch(trimmed)
+			if matches == nil {
+				return nil, fmt.Errorf("invalid member mapping on line %d: %s", lineNumber, trimmed)
+			}
+			class.Fields[matches[3]] = matches[2]
+			lastMethod = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+// metadata is an R8 metadata comment.
+type metadata struct {
+	Id       string `json:"id"`
+	FileName string `json:"fileName"`
+}
+
+// parseMetadata parses a `# {"id":...}` comment, returning nil for other comments.
+func parseMetadata(comment string) *metadata {
+	comment = strings.TrimSpace(strings.TrimPrefix(comment, "#"))
+	if !strings.HasPrefix(comment, "{") {
+		return nil
+	}
+
+	var parsed metadata
+	if err := json.Unmarshal([]byte(comment), &parsed); err != nil || parsed.Id == "" {
+		return nil
+	}
+	return &parsed
+}
+
+func atoi(value string) int {
+	number, _ := strconv.Atoi(value)
+	return number
+}
+
+// OriginalClassName returns the original name of a class, or the name unchanged if it
+// was not obfuscated.
+func (m *Mapping) OriginalClassName(obfuscated string) string {
+	if class, ok := m.classes[obfuscated]; ok {
+		return class.Original
+	}
+	return obfuscated
+}
+
+// Retrace returns the original frames for an obfuscated stack frame. If the frame is in
+// a method with inlined methods, an alternative contains a frame for each of them from the
+// innermost method outwards. There is more than one alternative if the frame is ambiguous,
+// for example because several overloaded methods were given the same obfuscated name and
+// there is no line number to tell them apart.
+//
+// Parameters:
+// - className: The obfuscated class name.
+// - methodName: The obfuscated method name.
+// - line: The line number in the frame, or 0 if it has none.
+//
+// Returns:
+// - [][]Frame: The alternative original frames, or nil if the class or method is not in the mapping.
+func (m *Mapping) Retrace(className string, methodName string, line int) [][]Frame {
+	class, ok := m.classes[className]
+	if !ok {
+		return nil
+	}
+
+	var candidates, unranged []*Method
+	for _, method := range class.Methods {
+		if method.Obfuscated != methodName {
+			continue
+		}
+		if method.StartLine == 0 && method.EndLine == 0 {
+			unranged = append(unranged, method)
+		} else if line == 0 || (line >= method.StartLine && line <= method.EndLine) {
+			candidates = append(candidates, method)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = unranged
+	}
+
+	var alternatives [][]Frame
+	seen := map[string]bool{}
+	for start := 0; start < len(candidates); {
+		end := start + 1
+		for end < len(candidates) && candidates[end].group == candidates[start].group {
+			end++
+		}
+
+		inlined := candidates[start:end]
+		frames := m.framesFor(class, inlined, line)
+		start = end
+
+		// Overloads and alternative line ranges can produce identical frames
+		key := fmt.Sprint(frames)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		alternatives = append(alternatives, frames)
+	}
+
+	return alternatives
+}
+
+// framesFor returns the original frames for a group of inlined methods, dropping any
+// synthesized methods unless every method in the group is synthesized.
+func (m *Mapping) framesFor(class *Class, inlined []*Method, line int) []Frame {
+	var frames, synthesized []Frame
+	for i, method := range inlined {
+		originalClass := class
+		if method.OriginalClass != "" {
+			originalClass = m.originalClasses[method.OriginalClass]
+		}
+
+		frame := Frame{
+			Class:  class.Original,
+			Method: method.Original,
+			Line:   originalLine(method, line, i == 0),
+		}
+		if method.OriginalClass != "" {
+			frame.Class = method.OriginalClass
+		}
+		frame.File = sourceFile(frame.Class, originalClass)
+
+		if method.Synthesized || (originalClass != nil && originalClass.Synthesized) {
+			synthesized = append(synthesized, frame)
+		} else {
+			frames = append(frames, frame)
+		}
+	}
+
+	if len(frames) == 0 {
+		return synthesized
+	}
+	return frames
+}
+
+// originalLine maps an obfuscated line number to the original line in a method. Only the
+// innermost inlined method is mapped using the line of the frame; the methods it was
+// inlined into are at the line of their call to the inlined method.
+func originalLine(method *Method, line int, isInnermost bool) int {
+	if method.OriginalStartLine == 0 {
+		// The line numbers were not changed
+		return line
+	}
+	if !isInnermost || method.OriginalStartLine == method.OriginalEndLine {
+		return method.OriginalStartLine
+	}
+	if line == 0 {
+		return 0
+	}
+	if method.OriginalEndLine-method.OriginalStartLine == method.EndLine-method.StartLine {
+		return method.OriginalStartLine + line - method.StartLine
+	}
+	return method.OriginalStartLine
+}
+
+// sourceFile returns the source file of a class, from the R8 metadata if there is any, or
+// otherwise the Java file named after its outermost class.
+func sourceFile(className string, class *Class) string {
+	if class != nil && class.SourceFile != "" {
+		return class.SourceFile
+	}
+
+	name := className[strings.LastIndex(className, ".")+1:]
+	if dollar := strings.Index(name, "$"); dollar > 0 {
+		name = name[:dollar]
+	}
+	return name + ".java"
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/cache"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+)
+
+// uploadCache looks up and records a single upload in the local upload ledger. A nil
+// uploadCache is valid and means that caching is disabled.
+type uploadCache struct {
+	ledger   *cache.Ledger
+	key      string
+	endpoint string
+}
+
+// newUploadCache creates the cache entry for an upload, keyed by the project, endpoint,
+// upload fields and the SHA-256 of each file. It returns nil if caching is disabled with
+// --no-cache, for dry runs and uploads that overwrite existing files, or if the cache cannot be
+// used, in which case the upload goes ahead as normal.
+func newUploadCache(apiKey string, endpoint string, uploadOptions map[string]string, fileFieldData map[string]FileField, options options.CLI, logger log.Logger) *uploadCache {
+	if options.Upload.NoCache || options.DryRun || isOverwrite(endpoint, uploadOptions) {
+		return nil
+	}
+
+	ledger, err := cache.Open(options.Upload.CacheDir)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Unable to use the upload cache: %s", err))
+		return nil
+	}
+
+	files := make(map[string]string, len(fileFieldData))
+	for name, file := range fileFieldData {
+		hash := sha256.New()
+		if err := file.writeContents(hash); err != nil {
+			// The upload reports the error when it fails to read the file
+			return nil
+		}
+		files[name] = file.formFileName() + ":" + hex.EncodeToString(hash.Sum(nil))
+	}
+
+	// The query string is left out of the recorded endpoint as it may contain the API key
+	recordedEndpoint := endpoint
+	if parsed, err := url.Parse(endpoint); err == nil {
+		parsed.RawQuery = ""
+		recordedEndpoint = parsed.String()
+	}
+
+	return &uploadCache{
+		ledger:   ledger,
+		key:      cache.Key(apiKey, endpoint, uploadOptions, files),
+		endpoint: recordedEndpoint,
+	}
+}
+
+// isOverwrite reports whether an upload replaces any existing file, with the overwrite field
+// or query parameter. These uploads are always sent, as the file may have been uploaded
+// before without it.
+func isOverwrite(endpoint string, uploadOptions map[string]string) bool {
+	if uploadOptions["overwrite"] == "true" {
+		return true
+	}
+	parsed, err := url.Parse(endpoint)
+	return err == nil && parsed.Query().Get("overwrite") == "true"
+}
+
+// lookup returns the entry for a previous successful upload of the same files, if any.
+func (c *uploadCache) lookup() (cache.Entry, bool) {
+	if c == nil {
+		return cache.Entry{}, false
+	}
+	return c.ledger.Get(c.key)
+}
+
+// record stores a successful upload. Failing to record it does not fail the upload.
+func (c *uploadCache) record(fileName string, logger log.Logger) {
+	if c == nil {
+		return
+	}
+
+	err := c.ledger.Record(c.key, cache.Entry{
+		File:       fileName,
+		Endpoint:   c.endpoint,
+		UploadedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+}
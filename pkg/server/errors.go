@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError is returned when the BugSnag API responds with an unsuccessful status code.
+//
+// Callers should use errors.As (or AsAPIError) to inspect the response rather than
+// matching on the error message.
+type APIError struct {
+	// Endpoint is the URL the request was sent to.
+	Endpoint   string `json:"endpoint"`
+	StatusCode int    `json:"statusCode"`
+	Status     string `json:"status"`
+	Body       string `json:"body,omitempty"`
+	// Message is the "error" (or "message") field of a JSON response body, if present.
+	Message string `json:"message,omitempty"`
+	// Errors is the "errors" field of a JSON response body, if present.
+	Errors []string `json:"errors,omitempty"`
+	// Warnings is the "warnings" field of a JSON response body, if present.
+	Warnings []string `json:"warnings,omitempty"`
+	// RetryAfter is the delay requested by the server in a Retry-After header, or zero if none was sent.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Status, e.Body)
+}
+
+// Retryable reports whether the request may succeed if it is sent again.
+//
+// Server errors, rate limiting and request timeouts are retryable; all other
+// client errors (e.g. 400, 401, 409 and 422) are terminal.
+func (e *APIError) Retryable() bool {
+	switch {
+	case e.StatusCode >= 500:
+		return true
+	case e.StatusCode == http.StatusTooManyRequests, e.StatusCode == http.StatusRequestTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDuplicate reports whether the upload was rejected because a matching file has already been uploaded.
+func (e *APIError) IsDuplicate() bool {
+	return e.StatusCode == http.StatusConflict
+}
+
+// IsNotFound reports whether the endpoint does not exist, which is the case for some
+// endpoints on older on-premise installations.
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// AsAPIError returns the *APIError in err's chain, if there is one.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsStatus reports whether err is, or wraps, an *APIError with the given status code.
+func IsStatus(err error, statusCode int) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+// isRetryable reports whether a failed request should be attempted again.
+// Errors that did not come from an API response, such as network failures, are always retryable.
+func isRetryable(err error) bool {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.Retryable()
+	}
+	return true
+}
+
+// apiErrorBody is the JSON body returned by the BugSnag API for unsuccessful requests.
+type apiErrorBody struct {
+	Error    string   `json:"error"`
+	Message  string   `json:"message"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// newAPIError builds an *APIError from an unsuccessful response and its body.
+//
+// If the body is JSON, its error, errors and warnings fields are parsed into the
+// error; a body that cannot be parsed is kept as-is in Body.
+func newAPIError(response *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Body:       string(body),
+	}
+
+	if response.Request != nil && response.Request.URL != nil {
+		apiErr.Endpoint = response.Request.URL.String()
+	}
+
+	var parsed apiErrorBody
+	if json.Unmarshal(body, &parsed) == nil {
+		apiErr.Message = parsed.Error
+		if apiErr.Message == "" {
+			apiErr.Message = parsed.Message
+		}
+		apiErr.Errors = parsed.Errors
+		apiErr.Warnings = parsed.Warnings
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable {
+		apiErr.RetryAfter = parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+	}
+
+	return apiErr
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number of
+// seconds or an HTTP date. It returns zero if the value is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"io"
+	"maps"
+	"mime/multipart"
+	"slices"
+	"sync"
+)
+
+// multipartBody is a multipart/form-data request body that is written directly from
+// its sources when the request is sent, so file contents are never held in memory.
+//
+// Parts are always written in the same order (files then fields, each sorted by name)
+// with a fixed boundary, which allows the body length to be calculated before it is written.
+type multipartBody struct {
+	boundary string
+	fields   map[string]string
+	files    map[string]FileField
+}
+
+// countingWriter discards everything written to it, keeping a count of the bytes.
+type countingWriter struct {
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.n += int64(len(p))
+	return len(p), nil
+}
+
+func newMultipartBody(fields map[string]string, files map[string]FileField) *multipartBody {
+	return &multipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+		fields:   fields,
+		files:    files,
+	}
+}
+
+func (body *multipartBody) newWriter(w io.Writer) (*multipart.Writer, error) {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(body.boundary); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// contentType returns the Content-Type header value for the body, including its boundary.
+func (body *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + body.boundary
+}
+
+// contentLength calculates the exact number of bytes writeTo will produce, by writing
+// the part headers and fields to a counter and adding the size of each file.
+func (body *multipartBody) contentLength() (int64, error) {
+	counter := &countingWriter{}
+	writer, err := body.newWriter(counter)
+	if err != nil {
+		return 0, err
+	}
+
+	var fileBytes int64
+	for _, key := range slices.Sorted(maps.Keys(body.files)) {
+		file := body.files[key]
+		if _, err := writer.CreateFormFile(key, file.formFileName()); err != nil {
+			return 0, err
+		}
+
+		size, err := file.size()
+		if err != nil {
+			return 0, err
+		}
+		fileBytes += size
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(body.fields)) {
+		if err := writer.WriteField(key, body.fields[key]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	return counter.n + fileBytes, nil
+}
+
+// writeTo writes the complete multipart body to w.
+func (body *multipartBody) writeTo(w io.Writer) error {
+	writer, err := body.newWriter(w)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(body.files)) {
+		if err := body.files[key].writeToForm(writer, key); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(body.fields)) {
+		if err := writer.WriteField(key, body.fields[key]); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// bodyReader streams a multipart body through a pipe. The body is only written once the
+// reader is first read from, so a request that is never sent does not leave a goroutine
+// blocked on the pipe, and closing the reader stops the body being written.
+type bodyReader struct {
+	once   sync.Once
+	body   *multipartBody
+	wrap   func(io.Writer) io.Writer
+	reader *io.PipeReader
+	writer *io.PipeWriter
+}
+
+// newReader returns a reader for the body. Writes to the pipe are passed through wrap, which
+// can be used to count the bytes sent.
+func (body *multipartBody) newReader(wrap func(io.Writer) io.Writer) io.ReadCloser {
+	reader, writer := io.Pipe()
+	return &bodyReader{body: body, wrap: wrap, reader: reader, writer: writer}
+}
+
+func (br *bodyReader) Read(p []byte) (int, error) {
+	br.once.Do(func() {
+		go func() {
+			br.writer.CloseWithError(br.body.writeTo(br.wrap(br.writer)))
+		}()
+	})
+	return br.reader.Read(p)
+}
+
+func (br *bodyReader) Close() error {
+	return br.reader.Close()
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// packageManifestName is the name of the manifest in a symbol bundle.
+const packageManifestName = "manifest.json"
+
+// apiKeyQueryParam is the query parameter that endpoints such as the Breakpad symbol
+// endpoint take the API key in.
+const apiKeyQueryParam = "api_key"
+
+// apiKeyField is the multipart field that most upload endpoints take the API key in.
+const apiKeyField = "apiKey"
+
+// packageManifestVersion is the version of the symbol bundle format.
+const packageManifestVersion = 1
+
+// PackageManifest lists the upload requests stored in a symbol bundle.
+type PackageManifest struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Requests  []PackagedRequest `json:"requests"`
+}
+
+// PackagedRequest is an upload request stored in a symbol bundle, with the exact multipart
+// fields and files that would have been sent.
+type PackagedRequest struct {
+	// The path of the upload endpoint, without its query string, resolved against the
+	// upload server when uploaded
+	Endpoint string `json:"endpoint"`
+	// The query parameters of the upload endpoint, other than the API key
+	Query map[string]string `json:"query,omitempty"`
+	// The name of the query parameter that the API key is sent in, if any. The API key is
+	// added to the query when the request is uploaded, so that it can be replaced.
+	ApiKeyParam string `json:"apiKeyParam,omitempty"`
+	// The name of the field that the API key is sent in, if any. The API key is not stored
+	// in the symbol bundle, and is added to the fields when the request is uploaded.
+	ApiKeyField string `json:"apiKeyField,omitempty"`
+	// The name of the uploaded file used in log messages and the upload cache
+	FileName string                  `json:"fileName"`
+	Fields   map[string]string       `json:"fields"`
+	Files    map[string]PackagedFile `json:"files"`
+}
+
+// EndpointPath returns the path and query string of the upload endpoint of a packaged
+// request, with the API key added to the query if it was sent in one.
+//
+// Parameters:
+//   - apiKey: The API key that the request is uploaded with.
+//
+// Returns:
+//   - string: The endpoint path to upload the request to.
+func (r PackagedRequest) EndpointPath(apiKey string) string {
+	query := url.Values{}
+	for key, value := range r.Query {
+		query.Set(key, value)
+	}
+	if r.ApiKeyParam != "" {
+		query.Set(r.ApiKeyParam, apiKey)
+	}
+	if len(query) == 0 {
+		return r.Endpoint
+	}
+	return r.Endpoint + "?" + query.Encode()
+}
+
+// PackagedFile is a file field of a packaged request.
+type PackagedFile struct {
+	// The file name sent in the multipart request
+	Name string `json:"name"`
+	// The path of the file's contents in the symbol bundle
+	Path string `json:"path"`
+}
+
+// Packager writes upload requests into a symbol bundle instead of sending them, so that
+// they can be uploaded later from another machine.
+type Packager struct {
+	mutex sync.Mutex
+	// The symbol bundle is written to a temporary file that is renamed once it is complete
+	path     string
+	file     *os.File
+	writer   *zip.Writer
+	manifest PackageManifest
+}
+
+var (
+	activePackagerMutex sync.Mutex
+	activePackager      *Packager
+)
+
+// StartPackage creates a symbol bundle and directs every following file upload request
+// into it until the Packager is closed.
+//
+// Parameters:
+//   - archivePath: The path to write the symbol bundle to.
+//
+// Returns:
+//   - *Packager: The packager, which must be closed to finish the symbol bundle.
+//   - error: An error if the symbol bundle cannot be created.
+func StartPackage(archivePath string) (*Packager, error) {
+	file, err := os.CreateTemp(filepath.Dir(archivePath), filepath.Base(archivePath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create symbol bundle %s: %w", archivePath, err)
+	}
+
+	packager := &Packager{
+		path:     archivePath,
+		file:     file,
+		writer:   zip.NewWriter(file),
+		manifest: PackageManifest{Version: packageManifestVersion, CreatedAt: time.Now().UTC(), Requests: []PackagedRequest{}},
+	}
+
+	activePackagerMutex.Lock()
+	activePackager = packager
+	activePackagerMutex.Unlock()
+
+	return packager, nil
+}
+
+// currentPackager returns the packager that requests are being written to, or nil if
+// requests are being sent.
+func currentPackager() *Packager {
+	activePackagerMutex.Lock()
+	defer activePackagerMutex.Unlock()
+	return activePackager
+}
+
+// Requests returns the number of requests written to the symbol bundle so far.
+func (p *Packager) Requests() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.manifest.Requests)
+}
+
+// add writes the files of an upload request into the symbol bundle and records the request
+// in its manifest.
+func (p *Packager) add(endpointPath string, fileName string, fields map[string]string, fileFieldData map[string]FileField) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	endpoint, err := url.Parse(endpointPath)
+	if err != nil {
+		return fmt.Errorf("invalid upload endpoint %s: %w", endpointPath, err)
+	}
+
+	request := PackagedRequest{
+		Endpoint: endpoint.Path,
+		FileName: fileName,
+		Fields:   maps.Clone(fields),
+		Files:    make(map[string]PackagedFile, len(fileFieldData)),
+	}
+
+	if _, ok := request.Fields[apiKeyField]; ok {
+		request.ApiKeyField = apiKeyField
+		delete(request.Fields, apiKeyField)
+	}
+
+	query := endpoint.Query()
+	if query.Has(apiKeyQueryParam) {
+		request.ApiKeyParam = apiKeyQueryParam
+		query.Del(apiKeyQueryParam)
+	}
+	if len(query) > 0 {
+		request.Query = make(map[string]string, len(query))
+		for key := range query {
+			request.Query[key] = query.Get(key)
+		}
+	}
+
+	index := len(p.manifest.Requests) + 1
+	for _, key := range slices.Sorted(maps.Keys(fileFieldData)) {
+		file := fileFieldData[key]
+		name := file.formFileName()
+		archivePath := fmt.Sprintf("files/%04d/%s/%s", index, key, path.Base(name))
+
+		writer, err := p.writer.Create(archivePath)
+		if err != nil {
+			return err
+		}
+		if err := file.writeContents(writer); err != nil {
+			return fmt.Errorf("cannot add %s to the symbol bundle: %w", name, err)
+		}
+		request.Files[key] = PackagedFile{Name: name, Path: archivePath}
+	}
+
+	p.manifest.Requests = append(p.manifest.Requests, request)
+	return nil
+}
+
+// Close writes the manifest and finishes the symbol bundle. Requests are sent again once the
+// packager is closed.
+//
+// Returns:
+//   - error: An error if the symbol bundle cannot be written.
+func (p *Packager) Close() error {
+	activePackagerMutex.Lock()
+	if activePackager == p {
+		activePackager = nil
+	}
+	activePackagerMutex.Unlock()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	writer, err := p.writer.Create(packageManifestName)
+	if err == nil {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(p.manifest)
+	}
+	if err == nil {
+		err = p.writer.Close()
+	}
+	if closeErr := p.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(p.file.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(p.file.Name(), p.path)
+	}
+	if err != nil {
+		os.Remove(p.file.Name())
+		return fmt.Errorf("cannot write symbol bundle %s: %w", p.path, err)
+	}
+	return nil
+}
+
+// PackageReader reads the requests stored in a symbol bundle.
+type PackageReader struct {
+	reader   *zip.ReadCloser
+	files    map[string]*zip.File
+	Manifest PackageManifest
+}
+
+// OpenPackage opens a symbol bundle written by a Packager.
+//
+// Parameters:
+//   - archivePath: The path to the symbol bundle.
+//
+// Returns:
+//   - *PackageReader: The reader, which must be closed once the requests have been sent.
+//   - error: An error if the symbol bundle cannot be read or was written by a newer version.
+func OpenPackage(archivePath string) (*PackageReader, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open symbol bundle %s: %w", archivePath, err)
+	}
+
+	packageReader := &PackageReader{reader: reader, files: make(map[string]*zip.File, len(reader.File))}
+	for _, file := range reader.File {
+		packageReader.files[file.Name] = file
+	}
+
+	manifestFile, ok := packageReader.files[packageManifestName]
+	if !ok {
+		reader.Close()
+		return nil, fmt.Errorf("%s is not a symbol bundle, it has no %s", archivePath, packageManifestName)
+	}
+	manifest, err := manifestFile.Open()
+	if err == nil {
+		err = json.NewDecoder(manifest).Decode(&packageReader.Manifest)
+		manifest.Close()
+	}
+	if err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("cannot read the manifest of symbol bundle %s: %w", archivePath, err)
+	}
+	if packageReader.Manifest.Version > packageManifestVersion {
+		reader.Close()
+		return nil, fmt.Errorf("%s was created by a newer version of the CLI, please update it to upload this symbol bundle", archivePath)
+	}
+
+	return packageReader, nil
+}
+
+// FileFields returns the file fields of a packaged request, read from the symbol bundle.
+//
+// Parameters:
+//   - request: A request from the manifest of the symbol bundle.
+//
+// Returns:
+//   - map[string]FileField: The file fields to send with the request.
+//   - error: An error if a file is missing from the symbol bundle.
+func (r *PackageReader) FileFields(request PackagedRequest) (map[string]FileField, error) {
+	fileFieldData := make(map[string]FileField, len(request.Files))
+	for key, file := range request.Files {
+		zipFile, ok := r.files[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("the symbol bundle is missing %s", file.Path)
+		}
+		fileFieldData[key] = packagedFile{name: file.Name, file: zipFile}
+	}
+	return fileFieldData, nil
+}
+
+// Close closes the symbol bundle.
+func (r *PackageReader) Close() error {
+	return r.reader.Close()
+}
+
+// packagedFile is a file field read from a symbol bundle. The file name is the one that was
+// recorded, so that the request is the same as it would have been when it was packaged.
+type packagedFile struct {
+	name string
+	file *zip.File
+}
+
+func (packaged packagedFile) formFileName() string {
+	return packaged.name
+}
+
+func (packaged packagedFile) size() (int64, error) {
+	return int64(packaged.file.UncompressedSize64), nil
+}
+
+func (packaged packagedFile) writeContents(w io.Writer) error {
+	reader, err := packaged.file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+func (packaged packagedFile) writeToForm(writer *multipart.Writer, key string) error {
+	part, err := writer.CreateFormFile(key, packaged.formFileName())
+	if err != nil {
+		return err
+	}
+	return packaged.writeContents(part)
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+// UploadJob is a single unit of upload work run by RunUploadJobs.
+//
+// Name identifies the job in the aggregated error report, and Run performs the
+// upload, logging through the logger it is given rather than a shared one so
+// that output can be kept in order when jobs run concurrently.
+type UploadJob struct {
+	Name string
+	Run  func(logger log.Logger) error
+}
+
+// UploadFailure records the error returned by a single failed UploadJob.
+type UploadFailure struct {
+	Name string
+	Err  error
+}
+
+// UploadErrors is returned by RunUploadJobs when one or more jobs fail.
+type UploadErrors struct {
+	Total    int
+	Failures []UploadFailure
+}
+
+func (e *UploadErrors) Error() string {
+	if len(e.Failures) == 1 {
+		return e.Failures[0].Err.Error()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d of %d uploads failed:", len(e.Failures), e.Total))
+	for _, failure := range e.Failures {
+		sb.WriteString(fmt.Sprintf("\n  - %s: %s", failure.Name, failure.Err.Error()))
+	}
+	return sb.String()
+}
+
+// Unwrap exposes the individual job errors to errors.Is and errors.As.
+func (e *UploadErrors) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// RunUploadJobs runs the given jobs on a bounded pool of workers.
+//
+// Every job is run, even if earlier jobs fail, and the failures are collected into
+// a single *UploadErrors. When more than one worker is used, each job logs into its
+// own buffer which is flushed to the logger in job order once all earlier jobs have
+// finished, so output for a file is never interleaved with output for another. The number
+// of jobs done is reported with the progress of each upload.
+//
+// Parameters:
+//   - jobs: The upload jobs to run.
+//   - concurrency: The maximum number of jobs to run at once. Values below 1 are treated as 1.
+//   - logger: Logger that job output is written to.
+//
+// Returns:
+//   - error: An *UploadErrors describing every failed job, or nil if all jobs succeed.
+func RunUploadJobs(jobs []UploadJob, concurrency int, logger log.Logger) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(jobs) {
+		concurrency = len(jobs)
+	}
+
+	results := make([]error, len(jobs))
+
+	// Count the files done, shown with the progress of each upload
+	countFiles := progress.startFiles(len(jobs), logger)
+	fileDone := func() {
+		if countFiles {
+			progress.fileDone()
+		}
+	}
+
+	if concurrency <= 1 {
+		for i, job := range jobs {
+			results[i] = job.Run(logger)
+			fileDone()
+		}
+	} else {
+		buffers := make([]*log.BufferedLogger, len(jobs))
+		done := make([]chan struct{}, len(jobs))
+		for i := range jobs {
+			buffers[i] = log.NewBufferedLogger()
+			done[i] = make(chan struct{})
+		}
+
+		queue := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range queue {
+					results[i] = jobs[i].Run(buffers[i])
+					close(done[i])
+				}
+			}()
+		}
+
+		go func() {
+			for i := range jobs {
+				queue <- i
+			}
+			close(queue)
+		}()
+
+		for i := range jobs {
+			<-done[i]
+			buffers[i].Flush(logger)
+			fileDone()
+		}
+		wg.Wait()
+	}
+
+	if countFiles {
+		progress.finishFiles()
+	}
+
+	var failures []UploadFailure
+	for i, err := range results {
+		if err != nil {
+			failures = append(failures, UploadFailure{Name: jobs[i].Name, Err: err})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &UploadErrors{Total: len(jobs), Failures: failures}
+	}
+
+	return nil
+}
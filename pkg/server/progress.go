@@ -0,0 +1,362 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+const (
+	// progressRedrawInterval is how often the progress bar is redrawn on a terminal.
+	progressRedrawInterval = 200 * time.Millisecond
+	// progressDelay is how long an upload runs before its progress is shown, so that small
+	// files do not flash a progress bar.
+	progressDelay = time.Second
+	// progressBarWidth is the number of characters in the progress bar.
+	progressBarWidth = 20
+	// progressNameWidth is the maximum number of characters of a file name in the progress bar.
+	progressNameWidth = 24
+)
+
+// uploadProgress is the number of bytes sent for the request body of a single upload.
+type uploadProgress struct {
+	name   string
+	logger log.Logger
+	total  atomic.Int64
+	sent   atomic.Int64
+	// The time that the current attempt started, and that its progress was last logged
+	started   time.Time
+	lastShown time.Time
+}
+
+// progressWriter counts the bytes of a request body as they are sent.
+type progressWriter struct {
+	writer   io.Writer
+	progress *uploadProgress
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.writer.Write(p)
+	pw.progress.sent.Add(int64(n))
+	return n, err
+}
+
+// progressTracker reports the progress of the running uploads, as a progress bar on a
+// terminal or as periodic log messages otherwise, along with the number of files that
+// have been processed by RunUploadJobs.
+type progressTracker struct {
+	mutex    sync.Mutex
+	enabled  bool
+	terminal bool
+	running  bool
+	// How often the progress of an upload is logged when the output is not a terminal
+	logInterval time.Duration
+	// The logger of the command, used for progress messages while the output of each
+	// upload is buffered by RunUploadJobs
+	logger     log.Logger
+	filesDone  int
+	filesTotal int
+	uploads    []*uploadProgress
+}
+
+var progress = &progressTracker{}
+
+// ConfigureProgress enables or disables progress reporting for uploads. Progress is drawn as
+// a progress bar if stderr is a terminal, and logged periodically otherwise.
+//
+// Parameters:
+//   - enabled: Whether progress is reported.
+//   - logInterval: How often the progress of an upload is logged when stderr is not a terminal.
+func ConfigureProgress(enabled bool, logInterval time.Duration) {
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	progress.enabled = enabled && logInterval > 0
+	progress.terminal = progress.enabled && log.StatusSupported()
+	progress.logInterval = logInterval
+}
+
+// startFiles starts counting the files processed by a set of upload jobs. Only the
+// outermost set of jobs is counted if jobs are nested.
+//
+// Returns:
+//   - bool: true if the files are being counted, and fileDone and finishFiles must be called.
+func (t *progressTracker) startFiles(total int, logger log.Logger) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.enabled || total < 2 || t.filesTotal > 0 {
+		return false
+	}
+	t.logger = logger
+	t.filesDone = 0
+	t.filesTotal = total
+	t.startTicker()
+	return true
+}
+
+// fileDone records that a file has been processed, logging the count when there is no
+// progress bar to show it in.
+func (t *progressTracker) fileDone() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.filesDone++
+	if t.terminal {
+		t.draw(time.Now())
+	} else {
+		t.logger.Info(fmt.Sprintf("%d of %d files done", t.filesDone, t.filesTotal))
+	}
+}
+
+// finishFiles stops counting files.
+func (t *progressTracker) finishFiles() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.logger = nil
+	t.filesDone = 0
+	t.filesTotal = 0
+	t.clearIfIdle()
+}
+
+// startUpload starts tracking the progress of an upload.
+//
+// Parameters:
+//   - name: The name of the uploaded file shown in progress messages.
+//   - logger: The logger for progress messages if no files are being counted.
+//
+// Returns:
+//   - *uploadProgress: The progress of the upload, or nil if progress is not reported.
+func (t *progressTracker) startUpload(name string, logger log.Logger) *uploadProgress {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.enabled {
+		return nil
+	}
+
+	now := time.Now()
+	upload := &uploadProgress{name: name, logger: logger, started: now, lastShown: now}
+	t.uploads = append(t.uploads, upload)
+	t.startTicker()
+	return upload
+}
+
+// finishUpload stops tracking the progress of an upload.
+func (t *progressTracker) finishUpload(upload *uploadProgress) {
+	if upload == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i, u := range t.uploads {
+		if u == upload {
+			t.uploads = append(t.uploads[:i], t.uploads[i+1:]...)
+			break
+		}
+	}
+	t.clearIfIdle()
+}
+
+// startAttempt resets the progress of an upload at the start of each attempt to send it.
+func (upload *uploadProgress) startAttempt(total int64) {
+	if upload == nil {
+		return
+	}
+
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	now := time.Now()
+	upload.total.Store(total)
+	upload.sent.Store(0)
+	upload.started = now
+	upload.lastShown = now
+}
+
+// writer returns a writer that counts the bytes of the request body written to w.
+func (upload *uploadProgress) writer(w io.Writer) io.Writer {
+	if upload == nil {
+		return w
+	}
+	return &progressWriter{writer: w, progress: upload}
+}
+
+// startTicker starts redrawing or logging the progress, if it is not already running. The
+// tracker's mutex must be held.
+func (t *progressTracker) startTicker() {
+	if t.running {
+		return
+	}
+	t.running = true
+
+	interval := progressRedrawInterval
+	if !t.terminal {
+		interval = min(progressRedrawInterval*5, t.logInterval)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			t.mutex.Lock()
+			if len(t.uploads) == 0 && t.filesTotal == 0 {
+				t.running = false
+				t.mutex.Unlock()
+				return
+			}
+			if t.terminal {
+				t.draw(time.Now())
+			} else {
+				t.logUploads(time.Now())
+			}
+			t.mutex.Unlock()
+		}
+	}()
+}
+
+// clearIfIdle removes the progress bar once there is nothing left to show. The tracker's
+// mutex must be held.
+func (t *progressTracker) clearIfIdle() {
+	if t.terminal && len(t.uploads) == 0 && t.filesTotal == 0 {
+		log.ClearStatus()
+	}
+}
+
+// draw draws the progress bar for the number of files done and the longest running upload.
+// The tracker's mutex must be held.
+func (t *progressTracker) draw(now time.Time) {
+	var parts []string
+	if t.filesTotal > 0 {
+		parts = append(parts, fmt.Sprintf("[%d/%d]", t.filesDone, t.filesTotal))
+	}
+
+	var shown *uploadProgress
+	for _, upload := range t.uploads {
+		if now.Sub(upload.started) >= progressDelay && upload.total.Load() > 0 {
+			shown = upload
+			break
+		}
+	}
+	if shown != nil {
+		parts = append(parts, formatProgressBar(shown, now))
+		if more := len(t.uploads) - 1; more > 0 {
+			parts = append(parts, fmt.Sprintf("+%d more", more))
+		}
+	}
+
+	if len(parts) == 0 {
+		log.ClearStatus()
+		return
+	}
+	log.SetStatus(strings.Join(parts, " "))
+}
+
+// logUploads logs the progress of each upload that has been running for longer than the
+// log interval since its progress was last logged. The tracker's mutex must be held.
+func (t *progressTracker) logUploads(now time.Time) {
+	for _, upload := range t.uploads {
+		if now.Sub(upload.lastShown) < t.logInterval || upload.total.Load() == 0 {
+			continue
+		}
+		upload.lastShown = now
+
+		logger := upload.logger
+		if t.logger != nil {
+			logger = t.logger
+		}
+		logger.Info(formatProgressMessage(upload, now))
+	}
+}
+
+// progressStats returns the bytes sent and total bytes of an upload, its percentage
+// complete, its throughput in bytes per second and the estimated time remaining.
+func progressStats(upload *uploadProgress, now time.Time) (int64, int64, int, float64, time.Duration) {
+	sent, total := upload.sent.Load(), upload.total.Load()
+	sent = min(sent, total)
+
+	percent := 0
+	if total > 0 {
+		percent = int(sent * 100 / total)
+	}
+
+	var rate float64
+	if elapsed := now.Sub(upload.started).Seconds(); elapsed > 0 {
+		rate = float64(sent) / elapsed
+	}
+
+	remaining := time.Duration(-1)
+	if rate > 0 {
+		remaining = time.Duration(float64(total-sent) / rate * float64(time.Second))
+	}
+
+	return sent, total, percent, rate, remaining
+}
+
+// formatProgressBar formats the progress of an upload for the progress bar, e.g.
+// "libil2cpp.so.sym  45% [=========>          ] 1.2 GB/2.7 GB 12.3 MB/s ETA 2m05s".
+func formatProgressBar(upload *uploadProgress, now time.Time) string {
+	sent, total, percent, rate, remaining := progressStats(upload, now)
+
+	name := upload.name
+	if len(name) > progressNameWidth {
+		name = "..." + name[len(name)-progressNameWidth+3:]
+	}
+
+	filled := percent * progressBarWidth / 100
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+
+	return fmt.Sprintf("%s %3d%% [%s] %s/%s %s/s ETA %s", name, percent, bar, formatBytes(sent), formatBytes(total), formatBytes(int64(rate)), formatRemaining(remaining))
+}
+
+// formatProgressMessage formats the progress of an upload as a log message, e.g.
+// "Uploading libil2cpp.so.sym: 45% of 2.7 GB sent (12.3 MB/s, about 2m05s remaining)".
+func formatProgressMessage(upload *uploadProgress, now time.Time) string {
+	_, total, percent, rate, remaining := progressStats(upload, now)
+
+	return fmt.Sprintf("Uploading %s: %d%% of %s sent (%s/s, about %s remaining)", upload.name, percent, formatBytes(total), formatBytes(int64(rate)), formatRemaining(remaining))
+}
+
+// formatBytes formats a number of bytes with a decimal unit, e.g. "12.3 MB".
+func formatBytes(n int64) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	for _, unit := range []string{"kB", "MB", "GB"} {
+		value /= 1000
+		if value < 1000 {
+			return fmt.Sprintf("%.1f %s", value, unit)
+		}
+	}
+	return fmt.Sprintf("%.1f TB", value/1000)
+}
+
+// formatRemaining formats the estimated time remaining, e.g. "2m05s".
+func formatRemaining(remaining time.Duration) string {
+	if remaining < 0 {
+		return "unknown"
+	}
+
+	remaining = remaining.Round(time.Second)
+	if remaining < time.Minute {
+		return fmt.Sprintf("%ds", int(remaining.Seconds()))
+	}
+	if remaining < time.Hour {
+		return fmt.Sprintf("%dm%02ds", int(remaining.Minutes()), int(remaining.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(remaining.Hours()), int(remaining.Minutes())%60)
+}
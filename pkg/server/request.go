@@ -2,8 +2,10 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"maps"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -19,7 +21,14 @@ import (
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
 )
 
+// FileField is a file that is sent as part of a multipart upload request.
 type FileField interface {
+	// formFileName returns the file name sent in the part's Content-Disposition header
+	formFileName() string
+	// size returns the number of bytes writeToForm will write for the file contents
+	size() (int64, error)
+	// writeContents writes the file contents, without any form encoding
+	writeContents(w io.Writer) error
 	writeToForm(writer *multipart.Writer, key string) error
 }
 
@@ -28,13 +37,37 @@ type requestBuilder func() (*http.Request, error)
 
 type LocalFile string
 
+func (localFile LocalFile) formFileName() string {
+	return filepath.Base(string(localFile))
+}
+
+func (localFile LocalFile) size() (int64, error) {
+	info, err := os.Stat(string(localFile))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (localFile LocalFile) writeContents(w io.Writer) error {
+	file, err := os.Open(string(localFile))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
+
 func (localFile LocalFile) writeToForm(writer *multipart.Writer, key string) error {
 	file, err := os.Open(string(localFile))
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	part, err := writer.CreateFormFile(key, filepath.Base(file.Name()))
+	part, err := writer.CreateFormFile(key, localFile.formFileName())
 	if err != nil {
 		return err
 	}
@@ -44,7 +77,6 @@ func (localFile LocalFile) writeToForm(writer *multipart.Writer, key string) err
 		return err
 	}
 
-	file.Close()
 	return nil
 }
 
@@ -53,8 +85,21 @@ type InMemoryFile struct {
 	Data []byte
 }
 
+func (inMemoryFile InMemoryFile) formFileName() string {
+	return inMemoryFile.Path
+}
+
+func (inMemoryFile InMemoryFile) size() (int64, error) {
+	return int64(len(inMemoryFile.Data)), nil
+}
+
+func (inMemoryFile InMemoryFile) writeContents(w io.Writer) error {
+	_, err := w.Write(inMemoryFile.Data)
+	return err
+}
+
 func (inMemoryFile InMemoryFile) writeToForm(writer *multipart.Writer, key string) error {
-	part, err := writer.CreateFormFile(key, inMemoryFile.Path)
+	part, err := writer.CreateFormFile(key, inMemoryFile.formFileName())
 	if err != nil {
 		return err
 	}
@@ -69,43 +114,38 @@ func (inMemoryFile InMemoryFile) writeToForm(writer *multipart.Writer, key strin
 
 // buildFileRequest constructs an HTTP request for file upload with specified field data.
 //
+// The request body is streamed from the files through a pipe once the request is sent,
+// rather than being buffered in memory, and the Content-Length is calculated up front
+// from the file sizes.
+//
 // Parameters:
 //   - url: The target URL for the file upload request.
 //   - fieldData: A map containing additional form fields for the request.
 //   - fileFieldData: A map containing file field names and their corresponding file paths.
+//   - upload: The progress of the upload, which is reset for the request, or nil.
+//   - idleTimeout: How long the request can go without sending any of the body, or 0 for no limit.
 //
 // Returns:
 //   - *http.Request: The constructed HTTP request.
 //   - error: An error if any step of the request construction fails.
-func buildFileRequest(url string, fieldData map[string]string, fileFieldData map[string]FileField) (*http.Request, error) {
-	body := &bytes.Buffer{}
-
-	writer := multipart.NewWriter(body)
+func buildFileRequest(url string, fieldData map[string]string, fileFieldData map[string]FileField, upload *uploadProgress, idleTimeout time.Duration) (*http.Request, error) {
+	body := newMultipartBody(fieldData, fileFieldData)
 
-	for key, value := range fileFieldData {
-		err := value.writeToForm(writer, key)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	for key, value := range fieldData {
-		err := writer.WriteField(key, value)
-		if err != nil {
-			return nil, err
-		}
+	contentLength, err := body.contentLength()
+	if err != nil {
+		return nil, err
 	}
 
-	writer.Close()
-
-	request, err := http.NewRequest("POST", url, body)
+	request, err := http.NewRequest("POST", url, body.newReader(upload.writer))
 	if err != nil {
 		return nil, err
 	}
 
-	request.Header.Add("Content-Type", writer.FormDataContentType())
+	upload.startAttempt(contentLength)
+	request.ContentLength = contentLength
+	request.Header.Add("Content-Type", body.contentType())
 
-	return request, nil
+	return withIdleTimeout(request, idleTimeout), nil
 }
 
 // ProcessFileRequest processes a file upload request by building an HTTP request,
@@ -124,54 +164,165 @@ func buildFileRequest(url string, fieldData map[string]string, fileFieldData map
 //   - error: An error if any step of the file processing fails. Nil if the process is successful.
 func ProcessFileRequest(apiKey string, endpointPath string, uploadOptions map[string]string, fileFieldData map[string]FileField, fileName string, options options.CLI, logger log.Logger) error {
 
+	result := newFileResult(fileName, uploadOptions, fileFieldData)
+
 	// Check if the fileName itself should be excluded based on exclude patterns
 	if len(options.Upload.Exclude) > 0 {
 		if utils.IsFileExcluded(fileName, options.Upload.Exclude) {
 			logger.Info(fmt.Sprintf("Skipping the upload of: %s (matches exclude pattern)", fileName))
+			result.Status = log.FileSkipped
+			result.Reason = "matches exclude pattern"
+			log.ReportFile(logger, result)
 			return nil
 		}
 	}
 
+	// Copy the upload options so callers can safely share a map between concurrent uploads
+	uploadOptions = maps.Clone(uploadOptions)
+	if uploadOptions == nil {
+		uploadOptions = make(map[string]string)
+	}
+
 	if apiKey != "" {
+		log.AddSecret(apiKey)
 		uploadOptions["apiKey"] = apiKey
 	} else {
-		return fmt.Errorf("missing api key, please specify using `--api-key`")
+		err := fmt.Errorf("missing api key, please specify using `--api-key`")
+		result.Status = log.FileFailed
+		result.Error = err.Error()
+		log.ReportFile(logger, result)
+		return err
+	}
+
+	// When packaging, the request is written to the symbol bundle to be uploaded later
+	if packager := currentPackager(); packager != nil && !options.DryRun {
+		if err := packager.add(endpointPath, fileName, uploadOptions, fileFieldData); err != nil {
+			result.Status = log.FileFailed
+			result.Error = err.Error()
+			log.ReportFile(logger, result)
+			return err
+		}
+		logger.Info(fmt.Sprintf("Packaged %s", filepath.Base(fileName)))
+		result.Status = log.FilePackaged
+		log.ReportFile(logger, result)
+		return nil
 	}
 
 	endpoint, err := endpoints.GetDefaultUploadEndpoint(apiKey, endpointPath, options)
 	if err != nil {
-		return fmt.Errorf("error getting upload endpoint: %w", err)
+		err = fmt.Errorf("error getting upload endpoint: %w", err)
+		result.Status = log.FileFailed
+		result.Error = err.Error()
+		log.ReportFile(logger, result)
+		return err
+	}
+	result.Endpoint = endpoint
+
+	// Skip files that have already been uploaded with the same contents and options
+	uploadCache := newUploadCache(apiKey, endpoint, uploadOptions, fileFieldData, options, logger)
+	if entry, ok := uploadCache.lookup(); ok {
+		logger.Info(fmt.Sprintf("Skipping the upload of: %s (already uploaded at %s)", fileName, entry.UploadedAt.Local().Format(time.DateTime)))
+		result.Status = log.FileSkipped
+		result.Reason = "already uploaded"
+		log.ReportFile(logger, result)
+		return nil
 	}
 
 	if !options.DryRun {
 		logger.Info(fmt.Sprintf("Uploading %s to %s", filepath.Base(fileName), endpoint))
 
+		var client *http.Client
+		client, err = httpClient(options)
+		if err != nil {
+			result.Status = log.FileFailed
+			result.Error = err.Error()
+			log.ReportFile(logger, result)
+			return err
+		}
+
+		upload := progress.startUpload(filepath.Base(fileName), logger)
+
 		// Create a builder function that constructs a fresh request for each attempt
 		buildRequest := func() (*http.Request, error) {
-			return buildFileRequest(endpoint, uploadOptions, fileFieldData)
+			return buildFileRequest(endpoint, uploadOptions, fileFieldData, upload, options.Upload.IdleTimeout)
 		}
 
-		err = processRequest(buildRequest, options.Upload.Timeout, options.Upload.Retries, logger)
+		result.Warnings, err = processRequest(buildRequest, client, newRetryPolicy(options), logger)
+		progress.finishUpload(upload)
 
 		if err != nil {
-			if strings.Contains(err.Error(), "409") {
-				logger.Warn(fmt.Sprintf("Duplicate file detected, skipping upload of %s", filepath.Base(fileName)))
-			} else {
+			duplicate := false
+			if apiErr, ok := AsAPIError(err); ok {
+				result.Warnings = apiErr.Warnings
+				duplicate = apiErr.IsDuplicate()
+			}
+
+			if !duplicate {
+				result.Status = log.FileFailed
+				result.Error = err.Error()
+				log.ReportFile(logger, result)
 				return err
 			}
+			logger.Warn(fmt.Sprintf("Duplicate file detected, skipping upload of %s", filepath.Base(fileName)))
+			result.Status = log.FileSkipped
+			result.Reason = "duplicate"
+			uploadCache.record(fileName, logger)
 		} else {
 			logger.Info("Uploaded " + filepath.Base(fileName))
+			result.Status = log.FileUploaded
+			uploadCache.record(fileName, logger)
 		}
 	} else {
 		logger.Info(fmt.Sprintf("(dryrun) Skipping upload of %s to %s", filepath.Base(fileName), endpoint))
 		logger.Debug("(dryrun) Upload payload:")
 		prettyUploadOptions, _ := utils.PrettyPrintMap(uploadOptions)
 		logger.Debug(prettyUploadOptions)
+		result.Status = log.FileDryRun
 	}
 
+	log.ReportFile(logger, result)
+
 	return nil
 }
 
+// identifierFields maps the upload fields that identify a build or file to the
+// names they are given in the command summary.
+var identifierFields = map[string]string{
+	"buildUUID":        "buildUuid",
+	"buildId":          "buildId",
+	"codeBundleId":     "codeBundleId",
+	"debug_identifier": "debugIdentifier",
+}
+
+// newFileResult creates the summary entry for a file upload, picking out the fields
+// that identify the build or file from the upload options.
+//
+// dSYMs are identified by their UUID, which is passed as the file name, so the path
+// of the dSYM itself is used as the file in the summary instead.
+func newFileResult(fileName string, uploadOptions map[string]string, fileFieldData map[string]FileField) log.FileResult {
+	result := log.FileResult{
+		File:        fileName,
+		Identifiers: make(map[string]string),
+	}
+
+	for field, identifier := range identifierFields {
+		if value := uploadOptions[field]; value != "" {
+			result.Identifiers[identifier] = value
+		}
+	}
+
+	if dsym, ok := fileFieldData["dsym"].(LocalFile); ok {
+		result.File = string(dsym)
+		result.Identifiers["dsymUuid"] = fileName
+	}
+
+	if len(result.Identifiers) == 0 {
+		result.Identifiers = nil
+	}
+
+	return result
+}
+
 // ProcessBuildRequest processes a build request by creating an HTTP request with the provided payload,
 // sending the request to the specified endpoint, and logging information based on the dryRun flag.
 // It handles the API key, constructs the endpoint URL, and manages retries in case of failures.
@@ -187,6 +338,7 @@ func ProcessBuildRequest(apiKey string, payload []byte, options options.CLI, log
 	if apiKey == "" {
 		return fmt.Errorf("missing api key, please specify using `--api-key`")
 	}
+	log.AddSecret(apiKey)
 
 	endpoint, err := endpoints.GetDefaultBuildEndpoint(apiKey, options)
 	if err != nil {
@@ -206,7 +358,12 @@ func ProcessBuildRequest(apiKey string, payload []byte, options options.CLI, log
 			return req, nil
 		}
 
-		err := processRequest(buildRequest, options.Upload.Timeout, options.Upload.Retries, logger)
+		client, err := httpClient(options)
+		if err != nil {
+			return err
+		}
+
+		_, err = processRequest(buildRequest, client, newRetryPolicy(options), logger)
 		if err != nil {
 			return err
 		}
@@ -214,7 +371,12 @@ func ProcessBuildRequest(apiKey string, payload []byte, options options.CLI, log
 		logger.Info(fmt.Sprintf("(dryrun) Skipping sending build information to %s", endpoint))
 		logger.Info("(dryrun) Build payload:")
 		prettyUploadOptions, _ := utils.PrettyPrintJson(string(payload))
-		fmt.Println(prettyUploadOptions)
+		if options.Output == log.OutputJson {
+			// Keep stdout free for the JSON summary
+			logger.Info(prettyUploadOptions)
+		} else {
+			fmt.Println(log.Redact(prettyUploadOptions))
+		}
 	}
 
 	return nil
@@ -222,102 +384,101 @@ func ProcessBuildRequest(apiKey string, payload []byte, options options.CLI, log
 
 // processRequest sends an HTTP request using sendRequest function with retry logic.
 // It builds a fresh request for each attempt to avoid state pollution from previous attempts.
-// It attempts to send the request multiple times, specified by retryCount parameter,
-// and waits for a short duration between each attempt.
-// If all attempts fail, it returns an error indicating the failure after the specified number of attempts.
+// Failed attempts are retried up to the number of retries in the policy, waiting for an
+// exponentially increasing, jittered delay (or the server's Retry-After delay) between attempts.
+// Terminal failures, such as 4xx responses other than 408 and 429, are not retried.
+// If all attempts fail, it returns an error indicating the failure after the number of attempts made.
 // Parameters:
 //   - buildRequest: A function that builds a fresh HTTP request for each attempt.
-//   - timeout: Timeout duration for the HTTP request in seconds.
-//   - retryCount: Number of times to retry the request in case of failure.
+//   - client: The HTTP client to send the request with.
+//   - policy: The retry policy to apply to failed attempts.
 //
 // Returns:
+//   - []string: Any warnings included in the response to the successful attempt.
 //   - error: An error indicating the reason for failure or nil if the request is successful.
-func processRequest(buildRequest requestBuilder, timeout int, retryCount int, logger log.Logger) error {
+//     Errors from unsuccessful responses wrap an *APIError.
+func processRequest(buildRequest requestBuilder, client *http.Client, policy retryPolicy, logger log.Logger) ([]string, error) {
 	var err error
 	i := 0
 	for {
 		// Build a fresh request for each attempt
 		request, buildErr := buildRequest()
 		if buildErr != nil {
-			return errors.Wrap(buildErr, "failed to build request")
+			return nil, errors.Wrap(buildErr, "failed to build request")
 		}
 
-		err = sendRequest(request, timeout, logger)
+		var warnings []string
+		warnings, err = sendRequest(request, client, logger)
 		if err == nil {
-			return nil
+			return warnings, nil
 		}
 
 		i++
 
-		if i > retryCount {
+		if i > policy.retries || !isRetryable(err) {
 			break
 		}
 
+		delay := policy.backoff(i, err)
+
 		logger.Warn(fmt.Sprintf("BugSnag API request attempt %d failed:", i))
 		logger.Warn(err.Error())
-		logger.Warn("Retrying...")
+		logger.Warn(fmt.Sprintf("Retrying in %s...", delay.Round(time.Millisecond)))
 
-		time.Sleep(time.Second)
+		time.Sleep(delay)
 	}
 
-	if err != nil {
-		return errors.Errorf("failed after %d attempts. %s", i, err.Error())
-	}
-
-	return nil
+	return nil, fmt.Errorf("failed after %d attempts. %w", i, err)
 }
 
-// sendRequest sends an HTTP request using the provided request object and timeout.
+// sendRequest sends an HTTP request using the provided request object and client.
 //
 // Parameters:
 //   - request: The HTTP request to be sent.
-//   - timeout: The timeout duration for the HTTP request in seconds.
+//   - client: The HTTP client to send the request with.
 //
 // Returns:
-//   - error: An error if any step of the request processing fails. Nil if the process is successful.
-func sendRequest(request *http.Request, timeout int, logger log.Logger) error {
-	// Configure transport to use HTTP/1.1 only
-	var protocols http.Protocols
-	protocols.SetHTTP1(true)
-	protocols.SetHTTP2(false)
-
-	transport := &http.Transport{
-		Protocols: &protocols,
-	}
-
-	client := &http.Client{
-		Timeout:   time.Duration(timeout) * time.Second,
-		Transport: transport,
-	}
-
+//   - []string: Any warnings included in a JSON response, which are also logged.
+//   - error: An error if any step of the request processing fails, or an *APIError if the
+//     server responds with an unsuccessful status code. Nil if the process is successful.
+func sendRequest(request *http.Request, client *http.Client, logger log.Logger) ([]string, error) {
 	response, err := client.Do(request)
 	if err != nil {
-		return fmt.Errorf("error sending request: %w", err)
+		// Report why the request was cancelled, such as the idle timeout expiring
+		if cause := context.Cause(request.Context()); cause != nil && cause != context.Canceled {
+			err = cause
+		}
+		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer response.Body.Close()
 
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("error reading body from response: %w", err)
+		return nil, fmt.Errorf("error reading body from response: %w", err)
 	}
 
 	contentType := response.Header.Get("Content-Type")
 
+	statusOK := response.StatusCode >= 200 && response.StatusCode < 300
+
+	var warnings []string
 	if strings.Contains(contentType, "application/json") {
-		warnings, err := utils.CheckResponseWarnings(responseBody)
-		if err != nil {
-			return err
+		responseWarnings, err := utils.CheckResponseWarnings(responseBody)
+		// An unparseable error response is still reported as an APIError below
+		if err != nil && statusOK {
+			return nil, err
 		}
 
-		for _, warning := range warnings {
-			logger.Warn(warning.(string))
+		for _, warning := range responseWarnings {
+			message := fmt.Sprint(warning)
+			logger.Warn(message)
+			warnings = append(warnings, message)
 		}
 	}
 
-	statusOK := response.StatusCode >= 200 && response.StatusCode < 300
 	if !statusOK {
-		return fmt.Errorf("%s: %s", response.Status, string(responseBody))
+		return nil, newAPIError(response, responseBody)
 	}
 
-	return nil
+	return warnings, nil
 }
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+)
+
+const (
+	defaultRetryBackoff    = time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+)
+
+// retryPolicy controls how many times a request is retried and how long to wait between attempts.
+type retryPolicy struct {
+	retries        int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// newRetryPolicy builds a retry policy from the upload options, falling back to the
+// default backoff durations when they are not set.
+func newRetryPolicy(options options.CLI) retryPolicy {
+	policy := retryPolicy{
+		retries:        options.Upload.Retries,
+		initialBackoff: options.Upload.RetryBackoff,
+		maxBackoff:     options.Upload.RetryMaxBackoff,
+	}
+
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = defaultRetryBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultRetryMaxBackoff
+	}
+	if policy.maxBackoff < policy.initialBackoff {
+		policy.maxBackoff = policy.initialBackoff
+	}
+
+	return policy
+}
+
+// backoff returns how long to wait before the next attempt, after the given number
+// of failed attempts.
+//
+// If the server asked for a delay with a Retry-After header, that delay is used, up to
+// the maximum backoff so that a large value cannot stall the CLI. Otherwise the delay
+// doubles after each attempt, up to the maximum backoff, and a random jitter of up to
+// half the delay is applied so that concurrent uploads do not retry in lockstep.
+func (p retryPolicy) backoff(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return min(apiErr.RetryAfter, p.maxBackoff)
+	}
+
+	delay := p.initialBackoff
+	for i := 1; i < attempt && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+)
+
+// idleConnTimeout is how long an idle connection is kept open for reuse by later requests.
+const idleConnTimeout = 90 * time.Second
+
+// transportConfig holds the options that the shared HTTP client is configured with, so that
+// a new client is only created when they change.
+type transportConfig struct {
+	proxy              string
+	caCert             string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+	http2              bool
+	concurrency        int
+	connectTimeout     time.Duration
+	tlsTimeout         time.Duration
+	responseTimeout    time.Duration
+}
+
+var (
+	sharedClientMutex  sync.Mutex
+	sharedClient       *http.Client
+	sharedClientConfig transportConfig
+)
+
+// NewTransport creates the HTTP transport used for requests to BugSnag, configured with the
+// proxy, TLS, protocol and timeout options. Idle connections are kept open so that later
+// requests to the same server reuse them rather than making a new TCP connection and TLS
+// handshake.
+//
+// Requests are sent through the proxy given by --proxy, or otherwise the proxy given by the
+// HTTPS_PROXY and HTTP_PROXY environment variables for hosts not excluded by NO_PROXY.
+//
+// Parameters:
+//   - options: The CLI options containing the proxy, TLS and timeout settings.
+//
+// Returns:
+//   - *http.Transport: The configured transport.
+//   - error: An error if the proxy URL is invalid or a certificate or key cannot be loaded.
+func NewTransport(options options.CLI) (*http.Transport, error) {
+	// Use HTTP/1.1 only unless HTTP/2 is enabled
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(options.Http2)
+
+	dialer := &net.Dialer{
+		Timeout:   options.Upload.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Protocols:             &protocols,
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   options.Upload.TlsTimeout,
+		ResponseHeaderTimeout: options.Upload.ResponseTimeout,
+		IdleConnTimeout:       idleConnTimeout,
+		MaxIdleConnsPerHost:   max(options.Concurrency, http.DefaultMaxIdleConnsPerHost),
+	}
+
+	if options.Proxy != "" {
+		proxyUrl, err := parseProxyUrl(options.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	tlsConfig, err := newTLSConfig(options.Globals)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// httpClient returns the HTTP client for requests to BugSnag. The client is shared by every
+// request, including concurrent uploads, so that connections are reused. It is only
+// replaced if it was created with different options.
+func httpClient(options options.CLI) (*http.Client, error) {
+	config := transportConfig{
+		proxy:              options.Proxy,
+		caCert:             options.CaCert,
+		clientCert:         options.ClientCert,
+		clientKey:          options.ClientKey,
+		insecureSkipVerify: options.InsecureSkipVerify,
+		http2:              options.Http2,
+		concurrency:        options.Concurrency,
+		connectTimeout:     options.Upload.ConnectTimeout,
+		tlsTimeout:         options.Upload.TlsTimeout,
+		responseTimeout:    options.Upload.ResponseTimeout,
+	}
+
+	sharedClientMutex.Lock()
+	defer sharedClientMutex.Unlock()
+
+	if sharedClient != nil && sharedClientConfig == config {
+		return sharedClient, nil
+	}
+
+	transport, err := NewTransport(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if sharedClient != nil {
+		sharedClient.CloseIdleConnections()
+	}
+	// The client has no overall timeout, as uploads of large files can take much longer than
+	// any fixed limit. Each stage of a request is limited by the transport instead, and the
+	// sending of a file by withIdleTimeout.
+	sharedClient = &http.Client{
+		Transport: transport,
+	}
+	sharedClientConfig = config
+
+	return sharedClient, nil
+}
+
+// idleTimeoutBody is a request body that cancels its request if none of the body is sent
+// for the idle timeout.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (body *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if err != nil {
+		body.timer.Stop()
+	} else if n > 0 {
+		body.timer.Reset(body.timeout)
+	}
+	return n, err
+}
+
+func (body *idleTimeoutBody) Close() error {
+	body.timer.Stop()
+	return body.ReadCloser.Close()
+}
+
+// withIdleTimeout fails a request if its body stops being sent for longer than the timeout,
+// for example if the connection has stalled, however long the whole body takes to send.
+// Once the body has been sent, the time to wait for a response is limited by the transport.
+//
+// Parameters:
+//   - request: The request to limit, which must have a body.
+//   - timeout: How long the request can go without sending any of its body, or 0 for no limit.
+//
+// Returns:
+//   - *http.Request: The request with the idle timeout applied.
+func withIdleTimeout(request *http.Request, timeout time.Duration) *http.Request {
+	if timeout <= 0 || request.Body == nil {
+		return request
+	}
+
+	ctx, cancel := context.WithCancelCause(request.Context())
+	timer := time.AfterFunc(timeout, func() {
+		cancel(fmt.Errorf("no data was sent for %s", timeout))
+	})
+
+	request = request.WithContext(ctx)
+	request.Body = &idleTimeoutBody{ReadCloser: request.Body, timer: timer, timeout: timeout}
+	return request
+}
+
+// parseProxyUrl parses the --proxy option. A URL without a scheme is treated as an HTTP proxy,
+// matching the handling of the proxy environment variables.
+func parseProxyUrl(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	proxyUrl, err := url.Parse(proxy)
+	if err != nil || proxyUrl.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxy)
+	}
+
+	switch proxyUrl.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return proxyUrl, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", proxyUrl.Scheme)
+	}
+}
+
+// newTLSConfig builds the TLS configuration for the --ca-cert, --client-cert, --client-key and
+// --insecure-skip-verify options, or returns nil to use the defaults if none are set.
+func newTLSConfig(globals options.Globals) (*tls.Config, error) {
+	if globals.CaCert == "" && globals.ClientCert == "" && globals.ClientKey == "" && !globals.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: globals.InsecureSkipVerify,
+	}
+
+	if globals.CaCert != "" {
+		// Trust the CA certificates as well as the system's, so that requests to other hosts
+		// through the same proxy still succeed
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		caCert, err := os.ReadFile(globals.CaCert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA certificate %s: %w", globals.CaCert, err)
+		}
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no PEM certificates found in CA certificate %s", globals.CaCert)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if globals.ClientCert != "" || globals.ClientKey != "" {
+		if globals.ClientCert == "" || globals.ClientKey == "" {
+			return nil, fmt.Errorf("--client-cert and --client-key must be used together")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(globals.ClientCert, globals.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate %s: %w", globals.ClientCert, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,365 @@
+package sourcemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// mapBuilder collects the sources, names and segments of a regular source map.
+type mapBuilder struct {
+	sources       []string
+	contents      []*string
+	ignored       []bool
+	metadata      []interface{}
+	sourceIndexes map[string]int
+	names         []string
+	nameIndexes   map[string]int
+	segments      []Segment
+}
+
+func newMapBuilder() *mapBuilder {
+	return &mapBuilder{sourceIndexes: map[string]int{}, nameIndexes: map[string]int{}}
+}
+
+// addSource returns the index of a source, adding it if needed. Sources are identified by
+// name, so a source that appears in more than one section is only listed once.
+func (b *mapBuilder) addSource(s *section, sourceIndex int) int {
+	source, content, metadata := s.sources[sourceIndex], s.contents[sourceIndex], s.metadata[sourceIndex]
+	if index, ok := b.sourceIndexes[source]; ok {
+		if b.contents[index] == nil {
+			b.contents[index] = content
+		}
+		if b.metadata[index] == nil {
+			b.metadata[index] = metadata
+		}
+		return index
+	}
+
+	index := len(b.sources)
+	b.sourceIndexes[source] = index
+	b.sources = append(b.sources, source)
+	b.contents = append(b.contents, content)
+	b.ignored = append(b.ignored, s.ignored[sourceIndex])
+	b.metadata = append(b.metadata, metadata)
+	return index
+}
+
+// addName returns the index of a name, adding it if needed.
+func (b *mapBuilder) addName(name string) int {
+	if index, ok := b.nameIndexes[name]; ok {
+		return index
+	}
+
+	index := len(b.names)
+	b.nameIndexes[name] = index
+	b.names = append(b.names, name)
+	return index
+}
+
+// add adds a segment at a generated position that maps to a source in a section, or to no
+// source if the section is nil.
+func (b *mapBuilder) add(line int, column int, s *section, segment Segment, name string) {
+	added := Segment{GeneratedLine: line, GeneratedColumn: column}
+	if s != nil && segment.HasSource && segment.SourceIndex < len(s.sources) {
+		added.HasSource = true
+		added.SourceIndex = b.addSource(s, segment.SourceIndex)
+		added.OriginalLine = segment.OriginalLine
+		added.OriginalColumn = segment.OriginalColumn
+		if name != "" {
+			added.HasName = true
+			added.NameIndex = b.addName(name)
+		}
+	}
+	b.segments = append(b.segments, added)
+}
+
+// sourceMap returns the source map in the same form as decoded JSON.
+func (b *mapBuilder) sourceMap(original map[string]interface{}) map[string]interface{} {
+	sources := make([]interface{}, len(b.sources))
+	for i, source := range b.sources {
+		sources[i] = source
+	}
+	names := make([]interface{}, len(b.names))
+	for i, name := range b.names {
+		names[i] = name
+	}
+
+	result := map[string]interface{}{
+		"version":  float64(3),
+		"sources":  sources,
+		"names":    names,
+		"mappings": EncodeMappings(b.segments),
+	}
+	// Hermes function offsets describe the final output, so they stay valid once composed
+	for _, key := range []string{"file", "debugId", "x_hermes_function_offsets"} {
+		if value, ok := original[key]; ok {
+			result[key] = value
+		}
+	}
+
+	var contents, ignoreList []interface{}
+	hasContents, hasMetadata := false, false
+	for i := range b.sources {
+		if b.metadata[i] != nil {
+			hasMetadata = true
+		}
+		if b.contents[i] != nil {
+			contents = append(contents, *b.contents[i])
+			hasContents = true
+		} else {
+			contents = append(contents, nil)
+		}
+		if b.ignored[i] {
+			ignoreList = append(ignoreList, float64(i))
+		}
+	}
+	if hasContents {
+		result["sourcesContent"] = contents
+	}
+	if len(ignoreList) > 0 {
+		result["ignoreList"] = ignoreList
+	}
+	if hasMetadata {
+		result["x_facebook_sources"] = b.metadata
+	}
+
+	return result
+}
+
+// addSections adds the segments of every section of a source map. If an inner source map is
+// given, segments in the sources that it describes are traced through it to its sources,
+// and the number of segments that were traced is returned.
+func (b *mapBuilder) addSections(sourceMap *SourceMap, inner *SourceMap, isInnerSource func(string) bool) int {
+	traced := 0
+	for i := range sourceMap.sections {
+		s := &sourceMap.sections[i]
+		for lineIndex, lineSegments := range s.lines {
+			for _, segment := range lineSegments {
+				line, column := s.line+lineIndex, segment.GeneratedColumn
+				if lineIndex == 0 {
+					column += s.column
+				}
+
+				name := ""
+				if segment.HasName && segment.NameIndex < len(s.names) {
+					name = s.names[segment.NameIndex]
+				}
+
+				if inner == nil || !segment.HasSource || segment.SourceIndex >= len(s.sources) || !isInnerSource(s.sources[segment.SourceIndex]) {
+					b.add(line, column, s, segment, name)
+					continue
+				}
+
+				innerSection, innerSegment, found := inner.originalSegment(segment.OriginalLine, segment.OriginalColumn)
+				if !found {
+					// The intermediate position has no original source
+					b.add(line, column, nil, segment, "")
+					continue
+				}
+				// Prefer the name in the original source over the intermediate one
+				if innerSegment.HasName && innerSegment.NameIndex < len(innerSection.names) {
+					name = innerSection.names[innerSegment.NameIndex]
+				}
+				b.add(line, column, innerSection, innerSegment, name)
+				traced++
+			}
+		}
+	}
+	return traced
+}
+
+// Flatten converts an index map into a regular source map, merging the sources and names of
+// its sections and offsetting their mappings to the position of each section. Regular
+// source maps are returned unchanged.
+//
+// Parameters:
+// - contents: The decoded JSON of the source map.
+//
+// Returns:
+// - map[string]interface{}: The flattened source map, in the same form as decoded JSON.
+// - error: An error if the source map is not valid.
+func Flatten(contents map[string]interface{}) (map[string]interface{}, error) {
+	if _, isIndexMap := contents["sections"]; !isIndexMap {
+		return contents, nil
+	}
+
+	sourceMap, err := Parse(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := newMapBuilder()
+	builder.addSections(sourceMap, nil, nil)
+	return builder.sourceMap(contents), nil
+}
+
+// Compose composes a chain of source maps from a multi-stage build into a single source map
+// that maps the final output directly to the original sources. Index maps in the chain are
+// flattened.
+//
+// The first source map is for the final output, and each following source map is for the
+// output of the build step before it, which must be one of the sources of the previous
+// source map. It is matched to that source by its `file` field, or to the only source if
+// the previous source map has a single source.
+//
+// Parameters:
+// - chain: The decoded JSON of each source map, from the final output back to the first step.
+//
+// Returns:
+// - map[string]interface{}: The composed source map, in the same form as decoded JSON.
+// - error: An error if a source map is not valid or cannot be matched to a source.
+func Compose(chain []map[string]interface{}) (map[string]interface{}, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no source maps to compose")
+	}
+
+	composed, err := Flatten(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("source map 1: %w", err)
+	}
+
+	for i, innerContents := range chain[1:] {
+		outer, err := Parse(composed)
+		if err != nil {
+			return nil, fmt.Errorf("source map %d: %w", i+1, err)
+		}
+		inner, err := Parse(innerContents)
+		if err != nil {
+			return nil, fmt.Errorf("source map %d: %w", i+2, err)
+		}
+
+		isInnerSource, err := innerSourceMatcher(outer, innerContents)
+		if err != nil {
+			return nil, fmt.Errorf("source map %d: %w", i+2, err)
+		}
+
+		builder := newMapBuilder()
+		if builder.addSections(outer, inner, isInnerSource) == 0 {
+			return nil, fmt.Errorf("source map %d: none of the mappings of the source map before it could be traced through it", i+2)
+		}
+		composed = builder.sourceMap(chain[0])
+	}
+
+	return composed, nil
+}
+
+// innerSourceMatcher returns a function that reports whether a source of the outer source
+// map is the file that the inner source map describes. The source is matched by the inner
+// source map's `file` field: exactly, then by either path ending with the other, and
+// finally by base name, using the first of these that matches only one source. Otherwise
+// it must be the only source of the outer source map, as Metro does not set the field.
+func innerSourceMatcher(outer *SourceMap, innerContents map[string]interface{}) (func(string) bool, error) {
+	sources := map[string]bool{}
+	for _, s := range outer.sections {
+		for _, source := range s.sources {
+			sources[source] = true
+		}
+	}
+
+	if file, _ := innerContents["file"].(string); file != "" {
+		file = filepath.ToSlash(file)
+		base := path.Base(file)
+		matchers := []func(string) bool{
+			func(source string) bool {
+				return source == file
+			},
+			func(source string) bool {
+				return strings.HasSuffix(source, "/"+strings.TrimPrefix(file, "./")) || strings.HasSuffix(file, "/"+strings.TrimPrefix(source, "./"))
+			},
+			func(source string) bool {
+				return path.Base(source) == base
+			},
+		}
+
+		// Each way of matching is only trusted if it picks out a single source
+		for _, matches := range matchers {
+			var matched []string
+			for source := range sources {
+				if matches(source) {
+					matched = append(matched, source)
+				}
+			}
+			if len(matched) == 1 {
+				return func(source string) bool { return source == matched[0] }, nil
+			}
+		}
+	}
+
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("the source map cannot be matched by its file field to one of the %d sources of the source map before it", len(sources))
+	}
+	return func(string) bool { return true }, nil
+}
+
+// ComposeFiles reads and composes a chain of source map files, as Compose does. The sources
+// of each source map after the first are made relative to the directory of the first, so
+// that they can still be found once composed.
+//
+// Parameters:
+// - paths: The paths of the source maps, from the final output back to the first build step.
+//
+// Returns:
+// - map[string]interface{}: The composed source map, in the same form as decoded JSON.
+// - error: An error if a source map cannot be read or composed.
+func ComposeFiles(paths []string) (map[string]interface{}, error) {
+	var chain []map[string]interface{}
+	for i, sourceMapPath := range paths {
+		data, err := os.ReadFile(sourceMapPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open source map at %s: %w", sourceMapPath, err)
+		}
+		var contents map[string]interface{}
+		if err := json.Unmarshal(data, &contents); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal source map at %s: %w", sourceMapPath, err)
+		}
+
+		if i > 0 {
+			rebaseSources(contents, filepath.Dir(sourceMapPath), filepath.Dir(paths[0]))
+		}
+		chain = append(chain, contents)
+	}
+
+	composed, err := Compose(chain)
+	if err != nil {
+		return nil, fmt.Errorf("composing %s: %w", strings.Join(paths, ", "), err)
+	}
+	return composed, nil
+}
+
+// rebaseSources rewrites the relative paths of the sources of a source map, including any
+// source root, from one directory to another. Absolute paths and URLs are left as they are.
+func rebaseSources(contents map[string]interface{}, from string, to string) {
+	if sections, ok := contents["sections"].([]interface{}); ok {
+		for _, untypedSection := range sections {
+			if section, ok := untypedSection.(map[string]interface{}); ok {
+				if sectionMap, ok := section["map"].(map[string]interface{}); ok {
+					rebaseSources(sectionMap, from, to)
+				}
+			}
+		}
+		return
+	}
+
+	sourceRoot, _ := contents["sourceRoot"].(string)
+	sources, _ := contents["sources"].([]interface{})
+	for i, untypedSource := range sources {
+		source, ok := untypedSource.(string)
+		if !ok || source == "" {
+			continue
+		}
+		if sourceRoot != "" {
+			source = strings.TrimSuffix(sourceRoot, "/") + "/" + source
+		}
+		if !strings.Contains(source, ":") && !filepath.IsAbs(source) {
+			if relative, err := filepath.Rel(to, filepath.Join(from, source)); err == nil {
+				source = filepath.ToSlash(relative)
+			}
+		}
+		sources[i] = source
+	}
+	delete(contents, "sourceRoot")
+}
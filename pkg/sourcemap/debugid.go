@@ -0,0 +1,120 @@
+package sourcemap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	debugIdCommentRe           = regexp.MustCompile(`(?m)^//# debugId=([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})[ \t]*\r?$`)
+	trailingSourceMappingURLRe = regexp.MustCompile(`(?m)^[ \t]*//[#@][ \t]*sourceMappingURL=\S*\s*\z`)
+)
+
+// debugIdSnippet registers the debug ID of a bundle against the URL of the bundle at runtime,
+// using the stack of an error created by the bundle to find its URL.
+const debugIdSnippet = `;!function(){try{var e="undefined"!=typeof window?window:"undefined"!=typeof global?global:"undefined"!=typeof globalThis?globalThis:"undefined"!=typeof self?self:{},n=(new e.Error).stack;n&&(e._debugIds=e._debugIds||{},e._debugIds[n]="%s")}catch(e){}}();`
+
+// GenerateDebugId derives a debug ID from the contents of a bundle, so that injecting debug
+// IDs into the same build always gives the same ID. The ID is formatted as a version 4 UUID.
+//
+// Parameters:
+// - bundle: The contents of the bundle, before a debug ID is injected.
+//
+// Returns:
+// - string: The debug ID.
+func GenerateDebugId(bundle []byte) string {
+	hash := sha256.Sum256(bundle)
+	id := hash[:16]
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
+// BundleDebugId returns the debug ID from the `//# debugId=` comment of a bundle, or an
+// empty string if it has none.
+func BundleDebugId(bundle []byte) string {
+	matches := debugIdCommentRe.FindAllSubmatch(bundle, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return strings.ToLower(string(matches[len(matches)-1][1]))
+}
+
+// SourceMapDebugId returns the debug ID of a decoded source map, or an empty string if it
+// has none. Older tools write the ID as `debug_id` rather than `debugId`.
+func SourceMapDebugId(sourceMap map[string]interface{}) string {
+	for _, key := range []string{"debugId", "debug_id"} {
+		if id, ok := sourceMap[key].(string); ok && id != "" {
+			return strings.ToLower(id)
+		}
+	}
+	return ""
+}
+
+// InjectBundleDebugId adds the runtime snippet and `//# debugId=` comment for a debug ID to
+// the end of a bundle. They are added before any `sourceMappingURL` comment, which must
+// remain the last comment in the file, and after the bundle's code so that none of its
+// positions move and the source map stays valid.
+//
+// Parameters:
+// - bundle: The contents of the bundle.
+// - debugId: The debug ID to inject.
+//
+// Returns:
+// - []byte: The bundle with the debug ID injected.
+func InjectBundleDebugId(bundle []byte, debugId string) []byte {
+	injected := fmt.Sprintf(debugIdSnippet+"\n//# debugId=%s\n", debugId, debugId)
+
+	end := len(bundle)
+	if location := trailingSourceMappingURLRe.FindIndex(bundle); location != nil {
+		end = location[0]
+	}
+
+	var result bytes.Buffer
+	result.Write(bundle[:end])
+	if end > 0 && bundle[end-1] != '\n' {
+		result.WriteByte('\n')
+	}
+	result.WriteString(injected)
+	result.Write(bundle[end:])
+	return result.Bytes()
+}
+
+// InjectSourceMapDebugId sets the `debugId` field of a source map. The field is added to the
+// start of the source map's JSON so that the rest of the file is left as it was.
+//
+// Parameters:
+// - sourceMap: The contents of the source map.
+// - debugId: The debug ID to inject.
+//
+// Returns:
+// - []byte: The source map with the debug ID injected.
+// - error: An error if the source map is not a JSON object.
+func InjectSourceMapDebugId(sourceMap []byte, debugId string) ([]byte, error) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(sourceMap, &decoded); err != nil {
+		return nil, fmt.Errorf("source map is not valid JSON: %w", err)
+	}
+
+	if _, ok := decoded["debugId"]; ok {
+		// Replacing an existing ID needs the whole source map to be encoded again
+		decoded["debugId"] = debugId
+		return json.Marshal(decoded)
+	}
+
+	start := bytes.IndexByte(sourceMap, '{')
+	field := fmt.Sprintf(`"debugId":%q`, debugId)
+	if len(decoded) > 0 {
+		field += ","
+	}
+
+	var result bytes.Buffer
+	result.Write(sourceMap[:start+1])
+	result.WriteString(field)
+	result.Write(sourceMap[start+1:])
+	return result.Bytes(), nil
+}
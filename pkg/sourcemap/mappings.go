@@ -0,0 +1,198 @@
+package sourcemap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Segment is a single decoded mapping from a position in the generated file to a
+// position in one of the original sources. Lines and columns are zero-based, and
+// columns are counted in UTF-16 code units as required by the source map format.
+type Segment struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	HasSource       bool
+	SourceIndex     int
+	OriginalLine    int
+	OriginalColumn  int
+	HasName         bool
+	NameIndex       int
+}
+
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// base64Values maps each base64 character to its value, or -1 if it is not valid.
+var base64Values = func() [256]int {
+	var values [256]int
+	for i := range values {
+		values[i] = -1
+	}
+	for i, c := range base64Digits {
+		values[c] = i
+	}
+	return values
+}()
+
+// DecodeMappings decodes the VLQ-encoded `mappings` field of a source map.
+//
+// Parameters:
+// - mappings: The encoded mappings.
+//
+// Returns:
+// - []Segment: The decoded segments, ordered by generated line.
+// - error: An error describing the first invalid segment, if any.
+func DecodeMappings(mappings string) ([]Segment, error) {
+	var segments []Segment
+	var sourceIndex, originalLine, originalColumn, nameIndex int
+
+	for line, lineMappings := range strings.Split(mappings, ";") {
+		generatedColumn := 0
+		if lineMappings == "" {
+			continue
+		}
+
+		for column, encoded := range strings.Split(lineMappings, ",") {
+			if encoded == "" {
+				return nil, fmt.Errorf("empty segment %d on line %d", column+1, line+1)
+			}
+
+			values, err := decodeVLQ(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("segment %d on line %d: %w", column+1, line+1, err)
+			}
+
+			switch len(values) {
+			case 1, 4, 5:
+			default:
+				return nil, fmt.Errorf("segment %d on line %d has %d fields, expected 1, 4 or 5", column+1, line+1, len(values))
+			}
+
+			generatedColumn += values[0]
+			segment := Segment{GeneratedLine: line, GeneratedColumn: generatedColumn}
+			if len(values) >= 4 {
+				sourceIndex += values[1]
+				originalLine += values[2]
+				originalColumn += values[3]
+				segment.HasSource = true
+				segment.SourceIndex = sourceIndex
+				segment.OriginalLine = originalLine
+				segment.OriginalColumn = originalColumn
+			}
+			if len(values) == 5 {
+				nameIndex += values[4]
+				segment.HasName = true
+				segment.NameIndex = nameIndex
+			}
+
+			if generatedColumn < 0 || sourceIndex < 0 || originalLine < 0 || originalColumn < 0 || nameIndex < 0 {
+				return nil, fmt.Errorf("segment %d on line %d has a negative position or index", column+1, line+1)
+			}
+
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments, nil
+}
+
+// EncodeMappings encodes segments as the VLQ-encoded `mappings` field of a source map.
+//
+// Parameters:
+// - segments: The segments to encode, in any order.
+//
+// Returns:
+// - string: The encoded mappings.
+func EncodeMappings(segments []Segment) string {
+	sorted := make([]Segment, len(segments))
+	copy(sorted, segments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		return a.GeneratedLine < b.GeneratedLine || (a.GeneratedLine == b.GeneratedLine && a.GeneratedColumn < b.GeneratedColumn)
+	})
+
+	var encoded strings.Builder
+	var sourceIndex, originalLine, originalColumn, nameIndex int
+	line, generatedColumn := 0, 0
+
+	for i, segment := range sorted {
+		if segment.GeneratedLine > line {
+			encoded.WriteString(strings.Repeat(";", segment.GeneratedLine-line))
+			line, generatedColumn = segment.GeneratedLine, 0
+		} else if i > 0 {
+			encoded.WriteByte(',')
+		}
+
+		encodeVLQ(&encoded, segment.GeneratedColumn-generatedColumn)
+		generatedColumn = segment.GeneratedColumn
+		if !segment.HasSource {
+			continue
+		}
+
+		encodeVLQ(&encoded, segment.SourceIndex-sourceIndex)
+		encodeVLQ(&encoded, segment.OriginalLine-originalLine)
+		encodeVLQ(&encoded, segment.OriginalColumn-originalColumn)
+		sourceIndex, originalLine, originalColumn = segment.SourceIndex, segment.OriginalLine, segment.OriginalColumn
+		if segment.HasName {
+			encodeVLQ(&encoded, segment.NameIndex-nameIndex)
+			nameIndex = segment.NameIndex
+		}
+	}
+
+	return encoded.String()
+}
+
+// encodeVLQ appends a base64 VLQ value.
+func encodeVLQ(encoded *strings.Builder, value int) {
+	vlq := value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+
+	for {
+		digit := vlq & 0x1f
+		vlq >>= 5
+		if vlq > 0 {
+			digit |= 0x20
+		}
+		encoded.WriteByte(base64Digits[digit])
+		if vlq == 0 {
+			return
+		}
+	}
+}
+
+// decodeVLQ decodes a sequence of base64 VLQ values.
+func decodeVLQ(encoded string) ([]int, error) {
+	var values []int
+	value, shift := 0, 0
+
+	for i := 0; i < len(encoded); i++ {
+		digit := base64Values[encoded[i]]
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base64 character %q", encoded[i])
+		}
+		if shift > 30 {
+			return nil, fmt.Errorf("value is too large")
+		}
+
+		value += (digit & 0x1f) << shift
+		if digit&0x20 != 0 {
+			shift += 5
+			continue
+		}
+
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+
+	if shift != 0 {
+		return nil, fmt.Errorf("incomplete value")
+	}
+
+	return values, nil
+}
@@ -0,0 +1,49 @@
+package sourcemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+)
+
+// ProcessComposeSourcemaps flattens a source map, or composes a chain of source maps into
+// one, writing the result to the output path or to stdout.
+//
+// Parameters:
+// - globalOptions: CLI options including the source maps to compose and the output path.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if a source map cannot be read or composed, or the result cannot be written.
+func ProcessComposeSourcemaps(globalOptions options.CLI, logger log.Logger) error {
+	composeOptions := globalOptions.ComposeSourcemaps
+
+	if len(composeOptions.SourceMaps) == 1 {
+		logger.Info(fmt.Sprintf("Flattening %s", composeOptions.SourceMaps[0]))
+	} else {
+		logger.Info(fmt.Sprintf("Composing %d source maps", len(composeOptions.SourceMaps)))
+	}
+
+	composed, err := ComposeFiles(composeOptions.SourceMaps)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(composed)
+	if err != nil {
+		return fmt.Errorf("failed to encode the composed source map: %w", err)
+	}
+
+	if composeOptions.OutputFile == "" {
+		_, err = os.Stdout.Write(encoded)
+		return err
+	}
+	if err := os.WriteFile(composeOptions.OutputFile, encoded, 0644); err != nil {
+		return fmt.Errorf("cannot write %s: %w", composeOptions.OutputFile, err)
+	}
+	logger.Info(fmt.Sprintf("Wrote the composed source map to %s", composeOptions.OutputFile))
+	return nil
+}
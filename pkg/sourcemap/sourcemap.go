@@ -0,0 +1,219 @@
+package sourcemap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Position is a zero-based position in an original source.
+type Position struct {
+	Source string
+	Line   int
+	Column int
+	Name   string
+}
+
+// SourceMap looks up the original positions of positions in a generated file.
+type SourceMap struct {
+	sections []section
+}
+
+// section is a regular source map, or one section of an index map.
+type section struct {
+	line    int
+	column  int
+	sources []string
+	// The content of each source, or nil if it was not included
+	contents []*string
+	// Whether each source is in the source map's ignore list
+	ignored []bool
+	// The Metro metadata of each source from x_facebook_sources, or nil if it has none
+	metadata []interface{}
+	names    []string
+	// The segments on each generated line, sorted by column
+	lines [][]Segment
+}
+
+// Parse parses the decoded JSON of a source map or index map.
+//
+// Parameters:
+// - contents: The decoded JSON of the source map.
+//
+// Returns:
+// - *SourceMap: The parsed source map.
+// - error: An error if the source map is not valid.
+func Parse(contents map[string]interface{}) (*SourceMap, error) {
+	untypedSections, isIndexMap := contents["sections"]
+	if !isIndexMap {
+		parsed, err := parseSection(contents, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &SourceMap{sections: []section{parsed}}, nil
+	}
+
+	sections, ok := untypedSections.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the sections field is not a list")
+	}
+
+	sourceMap := &SourceMap{}
+	for i, untypedSection := range sections {
+		indexSection, _ := untypedSection.(map[string]interface{})
+		sectionMap, _ := indexSection["map"].(map[string]interface{})
+		sectionOffset, _ := indexSection["offset"].(map[string]interface{})
+		if sectionMap == nil || sectionOffset == nil {
+			return nil, fmt.Errorf("section %d must have an offset and map", i)
+		}
+		line, _ := sectionOffset["line"].(float64)
+		column, _ := sectionOffset["column"].(float64)
+
+		parsed, err := parseSection(sectionMap, int(line), int(column))
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", i, err)
+		}
+		sourceMap.sections = append(sourceMap.sections, parsed)
+	}
+
+	sort.SliceStable(sourceMap.sections, func(i, j int) bool {
+		a, b := sourceMap.sections[i], sourceMap.sections[j]
+		return a.line < b.line || (a.line == b.line && a.column < b.column)
+	})
+
+	return sourceMap, nil
+}
+
+// parseSection parses a regular source map that starts at the given offset in the generated file.
+func parseSection(contents map[string]interface{}, line int, column int) (section, error) {
+	parsed := section{line: line, column: column}
+
+	untypedSources, ok := contents["sources"].([]interface{})
+	if !ok {
+		return parsed, fmt.Errorf("the source map has no sources list")
+	}
+	sourceRoot, _ := contents["sourceRoot"].(string)
+	for _, untypedSource := range untypedSources {
+		source, _ := untypedSource.(string)
+		if sourceRoot != "" && source != "" {
+			source = strings.TrimSuffix(sourceRoot, "/") + "/" + source
+		}
+		parsed.sources = append(parsed.sources, source)
+	}
+
+	parsed.contents = make([]*string, len(parsed.sources))
+	untypedContents, _ := contents["sourcesContent"].([]interface{})
+	for i, untypedContent := range untypedContents {
+		if content, ok := untypedContent.(string); ok && i < len(parsed.contents) {
+			parsed.contents[i] = &content
+		}
+	}
+
+	parsed.ignored = make([]bool, len(parsed.sources))
+	untypedIgnoreList, ok := contents["ignoreList"].([]interface{})
+	if !ok {
+		untypedIgnoreList, _ = contents["x_google_ignoreList"].([]interface{})
+	}
+	for _, untypedIndex := range untypedIgnoreList {
+		if index, ok := untypedIndex.(float64); ok && int(index) >= 0 && int(index) < len(parsed.ignored) {
+			parsed.ignored[int(index)] = true
+		}
+	}
+
+	parsed.metadata = make([]interface{}, len(parsed.sources))
+	untypedMetadata, _ := contents["x_facebook_sources"].([]interface{})
+	for i, metadata := range untypedMetadata {
+		if i < len(parsed.metadata) {
+			parsed.metadata[i] = metadata
+		}
+	}
+
+	untypedNames, _ := contents["names"].([]interface{})
+	for _, untypedName := range untypedNames {
+		name, _ := untypedName.(string)
+		parsed.names = append(parsed.names, name)
+	}
+
+	mappings, ok := contents["mappings"].(string)
+	if !ok {
+		return parsed, fmt.Errorf("the source map has no mappings")
+	}
+	segments, err := DecodeMappings(mappings)
+	if err != nil {
+		return parsed, fmt.Errorf("invalid mappings: %w", err)
+	}
+
+	for _, segment := range segments {
+		for len(parsed.lines) <= segment.GeneratedLine {
+			parsed.lines = append(parsed.lines, nil)
+		}
+		parsed.lines[segment.GeneratedLine] = append(parsed.lines[segment.GeneratedLine], segment)
+	}
+	for _, lineSegments := range parsed.lines {
+		sort.SliceStable(lineSegments, func(i, j int) bool {
+			return lineSegments[i].GeneratedColumn < lineSegments[j].GeneratedColumn
+		})
+	}
+
+	return parsed, nil
+}
+
+// OriginalPosition returns the original position that a position in the generated file
+// maps to, using the closest mapping at or before the column on the same line.
+//
+// Parameters:
+// - line: The zero-based line in the generated file.
+// - column: The zero-based column in the generated file, in UTF-16 code units.
+//
+// Returns:
+// - Position: The original position.
+// - bool: false if the position is not mapped to an original source.
+func (m *SourceMap) OriginalPosition(line int, column int) (Position, bool) {
+	s, segment, found := m.originalSegment(line, column)
+	if !found {
+		return Position{}, false
+	}
+
+	position := Position{
+		Source: s.sources[segment.SourceIndex],
+		Line:   segment.OriginalLine,
+		Column: segment.OriginalColumn,
+	}
+	if segment.HasName && segment.NameIndex < len(s.names) {
+		position.Name = s.names[segment.NameIndex]
+	}
+
+	return position, true
+}
+
+// originalSegment returns the segment that a position in the generated file maps to, and
+// the section it is in, if the segment maps to a source.
+func (m *SourceMap) originalSegment(line int, column int) (*section, Segment, bool) {
+	// Find the last section that starts at or before the position
+	index := sort.Search(len(m.sections), func(i int) bool {
+		s := m.sections[i]
+		return s.line > line || (s.line == line && s.column > column)
+	}) - 1
+	if index < 0 {
+		return nil, Segment{}, false
+	}
+
+	s := &m.sections[index]
+	line -= s.line
+	if line == 0 {
+		column -= s.column
+	}
+	if line >= len(s.lines) {
+		return nil, Segment{}, false
+	}
+
+	lineSegments := s.lines[line]
+	i := sort.Search(len(lineSegments), func(i int) bool {
+		return lineSegments[i].GeneratedColumn > column
+	}) - 1
+	if i < 0 || !lineSegments[i].HasSource || lineSegments[i].SourceIndex >= len(s.sources) {
+		return nil, Segment{}, false
+	}
+
+	return s, lineSegments[i], true
+}
@@ -0,0 +1,145 @@
+package symbolicate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/proguard"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+var (
+	// A Java stack frame, e.g. "	at a.b.c(SourceFile:12)"
+	javaFrameRe = regexp.MustCompile(`^(\s*)at ([\w$.]+)\.([^.(\s]+)\(([^)]*)\)(.*)$`)
+	// The first line of a Java exception, e.g. "Caused by: a.b: message"
+	javaExceptionRe = regexp.MustCompile(`^(\s*(?:Caused by: |Suppressed: |Exception in thread "[^"]*" )?)([\w$]+(?:\.[\w$]+)+)(:.*)?$`)
+)
+
+// SymbolicateAndroidProguard deobfuscates the exception class names and stack frames of a
+// Java or Kotlin stack trace using a Proguard or R8 mapping. Frames in inlined methods are
+// expanded to a frame for each method, and ambiguous frames are followed by their
+// alternatives, prefixed with "<OR>". Other lines are written unchanged.
+//
+// Parameters:
+// - stackTrace: The stack trace to deobfuscate.
+// - output: Where to write the deobfuscated stack trace.
+// - mapping: The mapping of the build that produced the stack trace.
+// - logger: logger instance.
+//
+// Returns:
+// - error: if the stack trace cannot be read or written.
+func SymbolicateAndroidProguard(stackTrace io.Reader, output io.Writer, mapping *proguard.Mapping, logger log.Logger) error {
+	scanner := bufio.NewScanner(stackTrace)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	writer := bufio.NewWriter(output)
+
+	mapped, total := 0, 0
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if matches := javaFrameRe.FindStringSubmatch(line); matches != nil {
+			total++
+			indent, className, methodName, location := matches[1], matches[2], matches[3], matches[4]
+
+			file, lineNumber := location, 0
+			if colon := strings.LastIndex(location, ":"); colon >= 0 {
+				if number, err := strconv.Atoi(location[colon+1:]); err == nil {
+					file, lineNumber = location[:colon], number
+				}
+			}
+
+			alternatives := mapping.Retrace(className, methodName, lineNumber)
+			if len(alternatives) == 0 {
+				logger.Debug(fmt.Sprintf("No mapping found for %s.%s", className, methodName))
+				fmt.Fprintln(writer, line)
+				continue
+			}
+			mapped++
+
+			for i, frames := range alternatives {
+				prefix := indent
+				if i > 0 {
+					prefix += "<OR> "
+				}
+				for _, frame := range frames {
+					if file == "Native Method" {
+						frame.File = file
+					}
+					fmt.Fprintf(writer, "%sat %s\n", prefix, formatJavaFrame(frame))
+				}
+			}
+			continue
+		}
+
+		if matches := javaExceptionRe.FindStringSubmatch(line); matches != nil {
+			line = matches[1] + mapping.OriginalClassName(matches[2]) + matches[3]
+		}
+		fmt.Fprintln(writer, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stack trace: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing stack trace: %w", err)
+	}
+
+	if mapped < total {
+		logger.Warn(fmt.Sprintf("Deobfuscated %d of %d frames", mapped, total))
+	} else {
+		logger.Info(fmt.Sprintf("Deobfuscated %d of %d frames", mapped, total))
+	}
+	return nil
+}
+
+// formatJavaFrame formats a frame in the form used by Java stack traces.
+func formatJavaFrame(frame proguard.Frame) string {
+	if frame.Line > 0 {
+		return fmt.Sprintf("%s.%s(%s:%d)", frame.Class, frame.Method, frame.File, frame.Line)
+	}
+	return fmt.Sprintf("%s.%s(%s)", frame.Class, frame.Method, frame.File)
+}
+
+// ProcessSymbolicateAndroidProguard deobfuscates a Java or Kotlin stack trace using the
+// Proguard or R8 mapping file at the given path, or of the given variant in an Android
+// project, writing the result to stdout.
+//
+// Parameters:
+// - globalOptions: CLI options including the stack trace and path to the mapping file.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if the mapping file cannot be found or read, or the stack trace cannot be read.
+func ProcessSymbolicateAndroidProguard(globalOptions options.CLI, logger log.Logger) error {
+	proguardOptions := globalOptions.Symbolicate.AndroidProguard
+
+	mappingFile := string(proguardOptions.Path)
+	if utils.IsDir(mappingFile) {
+		var err error
+		mappingFile, _, err = android.FindMappingFile(mappingFile, proguardOptions.Variant)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Reading mapping file %s", mappingFile))
+	mapping, err := proguard.ParseMappingFile(mappingFile)
+	if err != nil {
+		return err
+	}
+
+	stackTrace, err := openStackTrace(proguardOptions.StackTrace)
+	if err != nil {
+		return err
+	}
+	defer stackTrace.Close()
+
+	return SymbolicateAndroidProguard(stackTrace, os.Stdout, mapping, logger)
+}
@@ -0,0 +1,275 @@
+package symbolicate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+)
+
+// Frame formats: V8 (Chrome, Node.js and Hermes), e.g. "    at foo (https://example.com/app.js:1:2)",
+// and Firefox and Safari, e.g. "foo@https://example.com/app.js:1:2".
+var (
+	v8FrameRe    = regexp.MustCompile(`^(\s*)at (?:(.+?) \()?(.+?):(\d+):(\d+)\)?\s*$`)
+	geckoFrameRe = regexp.MustCompile(`^(\s*)(.*?)@(.+?):(\d+):(\d+)\s*$`)
+)
+
+// JsFrame is a stack frame from a JavaScript stack trace. Lines and columns are one-based.
+type JsFrame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+
+	indent string
+	isV8   bool
+}
+
+// ParseJsFrame parses a line of a V8, Firefox or Safari stack trace.
+//
+// Parameters:
+// - line: A line of the stack trace.
+//
+// Returns:
+// - *JsFrame: The frame, or nil if the line is not a stack frame.
+func ParseJsFrame(line string) *JsFrame {
+	isV8 := true
+	matches := v8FrameRe.FindStringSubmatch(line)
+	if matches == nil {
+		isV8 = false
+		matches = geckoFrameRe.FindStringSubmatch(line)
+	}
+	if matches == nil {
+		return nil
+	}
+
+	lineNumber, _ := strconv.Atoi(matches[4])
+	column, _ := strconv.Atoi(matches[5])
+	return &JsFrame{
+		Function: matches[2],
+		File:     matches[3],
+		Line:     lineNumber,
+		Column:   column,
+		indent:   matches[1],
+		isV8:     isV8,
+	}
+}
+
+// String formats the frame in the same style as the stack trace it was parsed from.
+func (f *JsFrame) String() string {
+	location := fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	if !f.isV8 {
+		return fmt.Sprintf("%s%s@%s", f.indent, f.Function, location)
+	}
+	if f.Function == "" {
+		return fmt.Sprintf("%sat %s", f.indent, location)
+	}
+	return fmt.Sprintf("%sat %s (%s)", f.indent, f.Function, location)
+}
+
+// jsSourceMaps finds and loads the source map for the bundle that each frame is in.
+type jsSourceMaps struct {
+	bundles []upload.SourceMapBundle
+	loaded  map[string]*sourcemap.SourceMap
+	logger  log.Logger
+}
+
+// forFile returns the source map of the bundle that best matches the file of a frame, which
+// is the bundle with the most trailing path components in common with the file's path.
+func (s *jsSourceMaps) forFile(file string) *sourcemap.SourceMap {
+	filePath := file
+	if parsed, err := url.Parse(file); err == nil && parsed.Path != "" {
+		filePath = parsed.Path
+	}
+	fileParts := strings.Split(strings.Trim(filepath.ToSlash(filePath), "/"), "/")
+
+	bestMatch, bestScore := -1, 0
+	for i, bundle := range s.bundles {
+		bundlePath := bundle.BundlePath
+		if bundlePath == "" {
+			bundlePath = strings.TrimSuffix(bundle.SourceMapPath, ".map")
+		}
+		bundleParts := strings.Split(filepath.ToSlash(bundlePath), "/")
+
+		score := 0
+		for score < len(fileParts) && score < len(bundleParts) &&
+			fileParts[len(fileParts)-1-score] == bundleParts[len(bundleParts)-1-score] {
+			score++
+		}
+		if score > bestScore {
+			bestMatch, bestScore = i, score
+		}
+	}
+	if bestMatch < 0 {
+		return nil
+	}
+
+	sourceMapPath := s.bundles[bestMatch].SourceMapPath
+	if sourceMap, loaded := s.loaded[sourceMapPath]; loaded {
+		return sourceMap
+	}
+
+	var sourceMap *sourcemap.SourceMap
+	contents, err := upload.ReadSourceMap(sourceMapPath, s.logger)
+	if err == nil {
+		sourceMap, err = sourcemap.Parse(contents)
+	}
+	if err != nil {
+		s.logger.Warn(fmt.Sprintf("Unable to use the source map %s: %s", sourceMapPath, err))
+	}
+	s.loaded[sourceMapPath] = sourceMap
+	return sourceMap
+}
+
+// SymbolicateJs maps each frame of a minified JavaScript stack trace to its original source
+// location. Lines that are not stack frames, and frames that cannot be mapped, are written
+// unchanged.
+//
+// The function name of each frame is taken from the name mapped at the call site in the
+// frame that called it, as the name mapped at a frame's own position is the function it was
+// calling. The minified name is kept if there is no name at the call site.
+//
+// Parameters:
+// - stackTrace: The stack trace to symbolicate.
+// - output: Where to write the symbolicated stack trace.
+// - sourceMapBundles: The bundles that the stack trace may refer to, and their source maps.
+// - logger: logger instance.
+//
+// Returns:
+// - error: if the stack trace cannot be read or written.
+func SymbolicateJs(stackTrace io.Reader, output io.Writer, sourceMapBundles []upload.SourceMapBundle, logger log.Logger) error {
+	var lines []string
+	scanner := bufio.NewScanner(stackTrace)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stack trace: %w", err)
+	}
+
+	sourceMaps := &jsSourceMaps{bundles: sourceMapBundles, loaded: map[string]*sourcemap.SourceMap{}, logger: logger}
+
+	frames := make([]*JsFrame, len(lines))
+	positions := make([]*sourcemap.Position, len(lines))
+	for i, line := range lines {
+		frame := ParseJsFrame(line)
+		if frame == nil {
+			continue
+		}
+		frames[i] = frame
+
+		sourceMap := sourceMaps.forFile(frame.File)
+		if sourceMap == nil {
+			logger.Debug(fmt.Sprintf("No source map found for %s", frame.File))
+			continue
+		}
+		// Source map columns are zero-based, whereas stack trace columns are one-based
+		position, found := sourceMap.OriginalPosition(frame.Line-1, frame.Column-1)
+		if !found {
+			logger.Debug(fmt.Sprintf("No mapping found for %s:%d:%d", frame.File, frame.Line, frame.Column))
+			continue
+		}
+		positions[i] = &position
+	}
+
+	mapped, total := 0, 0
+	writer := bufio.NewWriter(output)
+	for i, line := range lines {
+		if frames[i] != nil {
+			total++
+		}
+		if positions[i] == nil {
+			fmt.Fprintln(writer, line)
+			continue
+		}
+		mapped++
+
+		frame := *frames[i]
+		frame.File = positions[i].Source
+		frame.Line = positions[i].Line + 1
+		frame.Column = positions[i].Column + 1
+		if caller := nextFrame(frames, i); caller >= 0 && positions[caller] != nil && positions[caller].Name != "" {
+			frame.Function = positions[caller].Name
+		}
+		fmt.Fprintln(writer, frame.String())
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing stack trace: %w", err)
+	}
+
+	if mapped < total {
+		logger.Warn(fmt.Sprintf("Symbolicated %d of %d frames", mapped, total))
+	} else {
+		logger.Info(fmt.Sprintf("Symbolicated %d of %d frames", mapped, total))
+	}
+	return nil
+}
+
+// nextFrame returns the index of the next frame after index i, or -1 if there is none.
+func nextFrame(frames []*JsFrame, i int) int {
+	for j := i + 1; j < len(frames); j++ {
+		if frames[j] != nil {
+			return j
+		}
+	}
+	return -1
+}
+
+// openStackTrace opens the file containing a stack trace, or stdin if no file is given.
+func openStackTrace(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open stack trace: %w", err)
+	}
+	return file, nil
+}
+
+// ProcessSymbolicateJs symbolicates a JavaScript stack trace using the source maps found at
+// each path, writing the result to stdout.
+//
+// Parameters:
+// - globalOptions: CLI options including the stack trace and paths to the source maps.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if no source maps can be found or the stack trace cannot be read.
+func ProcessSymbolicateJs(globalOptions options.CLI, logger log.Logger) error {
+	jsOptions := globalOptions.Symbolicate.Js
+
+	var sourceMapBundles []upload.SourceMapBundle
+	for _, path := range jsOptions.Path {
+		// If the path is a .map file, treat the path as the source map itself
+		sourceMapPath, outputPath := upload.ResolveSourceMapArgument(path, jsOptions.SourceMap)
+
+		bundles, err := upload.ResolveSourceMapPaths(sourceMapPath, jsOptions.Bundle, outputPath, logger)
+		if err != nil {
+			return err
+		}
+		sourceMapBundles = append(sourceMapBundles, bundles...)
+	}
+	if len(sourceMapBundles) == 0 {
+		return fmt.Errorf("could not find a source map, please specify the path by using --source-map")
+	}
+
+	stackTrace, err := openStackTrace(jsOptions.StackTrace)
+	if err != nil {
+		return err
+	}
+	defer stackTrace.Close()
+
+	return SymbolicateJs(stackTrace, os.Stdout, sourceMapBundles, logger)
+}
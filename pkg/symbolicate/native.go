@@ -0,0 +1,312 @@
+package symbolicate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/elf"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// Native frame formats: Android tombstones and logcat crash dumps, e.g.
+// "    #00 pc 000000000001a2b4  /data/app/.../lib/arm64/libfoo.so (crash+20) (BuildId: 0f1e...)",
+// glibc backtraces, e.g. "/usr/lib/libfoo.so(+0x1a2b4) [0x7f0c2d41a2b4]", and lists of
+// addresses, each optionally followed by the library they are in, e.g. "0x1a2b4 libfoo.so".
+var (
+	tombstoneFrameRe = regexp.MustCompile(`^(\s*#\d+\s+pc\s+(?:0x)?)([0-9a-fA-F]+)(\s+)(\S+)(.*)$`)
+	backtraceFrameRe = regexp.MustCompile(`^(\s*)(\S+?)\(\+0x([0-9a-fA-F]+)\)(.*)$`)
+	addressRe        = regexp.MustCompile(`^(\s*)(?:0x)?([0-9a-fA-F]+)(?:\s+(\S+))?\s*$`)
+	buildIdRe        = regexp.MustCompile(`\(BuildId: ([0-9a-fA-F]+)\)`)
+)
+
+// nativeFrame is a frame of a native stack trace.
+type nativeFrame struct {
+	address uint64
+	library string
+	buildId string
+	// The indentation for the symbolized frames written below the frame
+	indent string
+}
+
+// parseNativeFrame parses a line of a tombstone, backtrace or address list, returning nil
+// if the line is not a stack frame.
+func parseNativeFrame(line string) *nativeFrame {
+	if matches := tombstoneFrameRe.FindStringSubmatch(line); matches != nil {
+		address, _ := strconv.ParseUint(matches[2], 16, 64)
+		frame := &nativeFrame{
+			address: address,
+			library: matches[4],
+			indent:  strings.Repeat(" ", len(matches[1])+len(matches[2])+len(matches[3])),
+		}
+		if buildId := buildIdRe.FindStringSubmatch(matches[5]); buildId != nil {
+			frame.buildId = strings.ToLower(buildId[1])
+		}
+		return frame
+	}
+
+	if matches := backtraceFrameRe.FindStringSubmatch(line); matches != nil {
+		address, _ := strconv.ParseUint(matches[3], 16, 64)
+		return &nativeFrame{address: address, library: matches[2], indent: matches[1] + "    "}
+	}
+
+	if matches := addressRe.FindStringSubmatch(line); matches != nil {
+		address, err := strconv.ParseUint(matches[2], 16, 64)
+		if err != nil {
+			return nil
+		}
+		return &nativeFrame{address: address, library: matches[3], indent: matches[1] + "    "}
+	}
+
+	return nil
+}
+
+// nativeLibraries finds and loads the symbol file for the library that each frame is in.
+type nativeLibraries struct {
+	byBuildId map[string]string
+	byName    map[string][]string
+	buildIds  map[string]string
+	loaded    map[string]*elf.Symbolizer
+	warned    map[string]bool
+	logger    log.Logger
+}
+
+// newNativeLibraries indexes symbol files by their build ID and the name of the library.
+// Where a stripped library and its separate debug file share a build ID, the one with
+// DWARF debug information is used.
+func newNativeLibraries(symbolFiles []string, logger log.Logger) *nativeLibraries {
+	libraries := &nativeLibraries{
+		byBuildId: map[string]string{},
+		byName:    map[string][]string{},
+		buildIds:  map[string]string{},
+		loaded:    map[string]*elf.Symbolizer{},
+		warned:    map[string]bool{},
+		logger:    logger,
+	}
+
+	for _, file := range symbolFiles {
+		name := strings.TrimSuffix(filepath.Base(file), ".debug")
+		libraries.byName[name] = append(libraries.byName[name], file)
+
+		buildId, err := elf.GetBuildId(file)
+		if err != nil {
+			logger.Debug(fmt.Sprintf("No build ID found in %s: %s", file, err))
+			continue
+		}
+		libraries.buildIds[file] = buildId
+		if existing, ok := libraries.byBuildId[buildId]; ok {
+			if symbolizer := libraries.load(existing); symbolizer != nil && symbolizer.HasDwarf {
+				continue
+			}
+		}
+		libraries.byBuildId[buildId] = file
+	}
+
+	return libraries
+}
+
+// forFrame returns the symbolizer for the library that a frame is in. Frames with a build
+// ID are only matched to a symbol file with the same build ID, as the addresses of any
+// other build of the library would be wrong.
+func (l *nativeLibraries) forFrame(frame *nativeFrame) *elf.Symbolizer {
+	if frame.buildId != "" {
+		if file, ok := l.byBuildId[frame.buildId]; ok {
+			return l.load(file)
+		}
+		l.logger.Debug(fmt.Sprintf("No symbol file found with build ID %s for %s", frame.buildId, frame.library))
+		return nil
+	}
+
+	name := filepath.Base(frame.library)
+	files := l.byName[name]
+	if len(files) == 0 {
+		l.logger.Debug(fmt.Sprintf("No symbol file found for %s", frame.library))
+		return nil
+	}
+
+	// A library and its separate debug file are the same build, so are not ambiguous
+	file := files[0]
+	if buildId, ok := l.buildIds[file]; ok {
+		file = l.byBuildId[buildId]
+	}
+	for _, other := range files[1:] {
+		if l.buildIds[other] != l.buildIds[files[0]] || l.buildIds[other] == "" {
+			if !l.warned[name] {
+				l.logger.Warn(fmt.Sprintf("Found more than one build of %s and the stack trace has no build ID to choose between them, using %s", name, file))
+				l.warned[name] = true
+			}
+			break
+		}
+	}
+	return l.load(file)
+}
+
+// load reads a symbol file, returning nil if it cannot be read.
+func (l *nativeLibraries) load(file string) *elf.Symbolizer {
+	if symbolizer, loaded := l.loaded[file]; loaded {
+		return symbolizer
+	}
+
+	symbolizer, err := elf.NewSymbolizer(file)
+	if err != nil {
+		l.logger.Warn(fmt.Sprintf("Unable to use the symbol file %s: %s", file, err))
+		symbolizer = nil
+	}
+	l.loaded[file] = symbolizer
+	return symbolizer
+}
+
+// SymbolicateNative resolves each frame of a native stack trace to the functions, source
+// files and lines at its address, in the style of ndk-stack. Each frame is written as it
+// was, followed by the function and source location of any inlined functions at the
+// address and then of the function that contains it. Lines that are not stack frames, and
+// frames that cannot be resolved, are written unchanged.
+//
+// Parameters:
+// - stackTrace: The tombstone, backtrace or list of addresses to symbolicate.
+// - output: Where to write the symbolicated stack trace.
+// - symbolFiles: The ELF symbol files for the libraries the stack trace may refer to.
+// - library: The library that addresses without a library name are in, if there is more than one symbol file.
+// - logger: logger instance.
+//
+// Returns:
+// - error: if the stack trace cannot be read or written.
+func SymbolicateNative(stackTrace io.Reader, output io.Writer, symbolFiles []string, library string, logger log.Logger) error {
+	libraries := newNativeLibraries(symbolFiles, logger)
+	if library == "" && len(symbolFiles) == 1 {
+		library = symbolFiles[0]
+	}
+
+	scanner := bufio.NewScanner(stackTrace)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	writer := bufio.NewWriter(output)
+
+	mapped, total := 0, 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(writer, line)
+
+		frame := parseNativeFrame(line)
+		if frame == nil {
+			continue
+		}
+		total++
+		if frame.library == "" {
+			frame.library = library
+		}
+
+		symbolizer := libraries.forFrame(frame)
+		if symbolizer == nil {
+			continue
+		}
+		frames := symbolizer.Symbolize(frame.address)
+		if len(frames) == 0 {
+			logger.Debug(fmt.Sprintf("No function found at 0x%x in %s", frame.address, frame.library))
+			continue
+		}
+		mapped++
+
+		for _, symbolized := range frames {
+			fmt.Fprintf(writer, "%s%s\n", frame.indent, symbolized.Function)
+			if symbolized.File != "" {
+				fmt.Fprintf(writer, "%s%s\n", frame.indent, formatSourceLocation(symbolized))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stack trace: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("writing stack trace: %w", err)
+	}
+
+	if mapped < total {
+		logger.Warn(fmt.Sprintf("Symbolicated %d of %d frames", mapped, total))
+	} else {
+		logger.Info(fmt.Sprintf("Symbolicated %d of %d frames", mapped, total))
+	}
+	return nil
+}
+
+// formatSourceLocation formats the file, line and column of a frame.
+func formatSourceLocation(frame elf.SymbolizedFrame) string {
+	if frame.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", frame.File, frame.Line, frame.Column)
+	}
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
+
+// findNativeSymbolFiles returns the ELF symbol files at a path. For an Android project, these
+// are the libraries in the merged_native_libs directory of the given variant, or of every
+// variant if none is given.
+func findNativeSymbolFiles(path string, variant string, logger log.Logger) ([]string, error) {
+	if utils.IsDir(path) {
+		if libPath, err := android.FindNativeLibPath([]string{"android", "app", "build", "intermediates", "merged_native_libs"}, path); err == nil && utils.IsDir(libPath) {
+			path = libPath
+			if variant != "" {
+				path = filepath.Join(libPath, variant)
+			}
+			logger.Debug(fmt.Sprintf("Using native libraries in %s", path))
+		}
+	}
+
+	fileList, err := utils.BuildFileList([]string{path})
+	if err != nil {
+		return nil, fmt.Errorf("building file list from %q: %w", path, err)
+	}
+
+	var symbolFiles []string
+	for _, file := range fileList {
+		if !strings.HasSuffix(file, ".so") && !strings.HasSuffix(file, ".so.debug") && !strings.HasSuffix(file, ".debug") {
+			continue
+		}
+		if ok, err := utils.IsSymbolFile(file); err != nil {
+			return nil, err
+		} else if ok {
+			symbolFiles = append(symbolFiles, file)
+		}
+	}
+	return symbolFiles, nil
+}
+
+// ProcessSymbolicateNative symbolicates an Android tombstone, a glibc backtrace or a list of
+// addresses using the ELF symbol files found at each path, writing the result to stdout.
+//
+// Parameters:
+// - globalOptions: CLI options including the stack trace and paths to the symbol files.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if no symbol files can be found or the stack trace cannot be read.
+func ProcessSymbolicateNative(globalOptions options.CLI, logger log.Logger) error {
+	nativeOptions := globalOptions.Symbolicate.Native
+
+	var symbolFiles []string
+	for _, path := range nativeOptions.Path {
+		files, err := findNativeSymbolFiles(path, nativeOptions.Variant, logger)
+		if err != nil {
+			return err
+		}
+		symbolFiles = append(symbolFiles, files...)
+	}
+	if len(symbolFiles) == 0 {
+		return fmt.Errorf("could not find any .so symbol files in %s", strings.Join(nativeOptions.Path, ", "))
+	}
+	logger.Info(fmt.Sprintf("Found %d symbol files", len(symbolFiles)))
+
+	stackTrace, err := openStackTrace(nativeOptions.StackTrace)
+	if err != nil {
+		return err
+	}
+	defer stackTrace.Close()
+
+	return SymbolicateNative(stackTrace, os.Stdout, symbolFiles, nativeOptions.Library, logger)
+}
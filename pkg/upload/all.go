@@ -10,8 +10,9 @@ import (
 // All processes and uploads all files specified by the upload options.
 //
 // It builds a list of files from the given path, applies any specified upload options
-// (such as overwriting existing files), and uploads each file individually.
-// The field name for the file upload can be customized via the "fileNameField" option.
+// (such as overwriting existing files), and uploads each file individually using the
+// upload worker pool. The field name for the file upload can be customized via the
+// "fileNameField" option.
 //
 // Parameters:
 //   - options: CLI options containing upload settings and API key.
@@ -38,29 +39,33 @@ func All(options options.CLI, logger log.Logger) error {
 		uploadOptions[key] = value
 	}
 
-	for _, file := range fileList {
-		fileFieldData := make(map[string]server.FileField)
+	fileNameField := "file"
+	if uploadOptions["fileNameField"] != "" {
+		fileNameField = uploadOptions["fileNameField"]
+	}
+	delete(uploadOptions, "fileNameField")
 
-		if uploadOptions["fileNameField"] != "" {
-			fileFieldData[uploadOptions["fileNameField"]] = server.LocalFile(file)
-			delete(uploadOptions, "fileNameField")
-		} else {
-			fileFieldData["file"] = server.LocalFile(file)
-		}
+	var jobs []server.UploadJob
+	for _, file := range fileList {
+		jobs = append(jobs, server.UploadJob{
+			Name: file,
+			Run: func(logger log.Logger) error {
+				fileFieldData := map[string]server.FileField{
+					fileNameField: server.LocalFile(file),
+				}
 
-		err := server.ProcessFileRequest(
-			options.ApiKey,
-			"",
-			uploadOptions,
-			fileFieldData,
-			file,
-			options,
-			logger,
-		)
-		if err != nil {
-			return err
-		}
+				return server.ProcessFileRequest(
+					options.ApiKey,
+					"",
+					uploadOptions,
+					fileFieldData,
+					file,
+					options,
+					logger,
+				)
+			},
+		})
 	}
 
-	return nil
+	return server.RunUploadJobs(jobs, options.Concurrency, logger)
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/elf"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
@@ -101,6 +102,7 @@ func populateMetadataFromManifest(opts *options.CLI, ndkOpts *options.AndroidNdk
 		for _, metaData := range manifestData.Application.MetaData {
 			if metaData.Name == "com.bugsnag.android.API_KEY" {
 				opts.ApiKey = metaData.Value
+				log.AddSecret(opts.ApiKey)
 				logger.Debug(fmt.Sprintf("API key found: %s", opts.ApiKey))
 				break
 			}
@@ -130,7 +132,7 @@ func populateMetadataFromManifest(opts *options.CLI, ndkOpts *options.AndroidNdk
 // It performs the following steps:
 //   - Resolves native libraries and variant information from project paths.
 //   - Parses metadata from AndroidManifest.xml if needed.
-//   - Extracts debug symbols from .so files using objcopy.
+//   - Extracts debug symbols from .so files, using objcopy if requested.
 //   - Uploads the resulting .so.sym files and metadata to Bugsnag.
 //
 // Parameters:
@@ -184,13 +186,13 @@ func ProcessAndroidNDK(opts options.CLI, logger log.Logger) error {
 		logger.Debug(fmt.Sprintf("Using %s as the project root", ndkOpts.ProjectRoot))
 	}
 
-	for _, file := range fileList {
-		symbols := make(map[string]string)
+	symbols := make(map[string]string)
 
+	for _, file := range fileList {
 		if strings.HasSuffix(file, ".so.sym") {
 			symbols[file] = file
 		} else if soRegex.MatchString(file) {
-			if objCopyPath == "" {
+			if ndkOpts.UseObjcopy && objCopyPath == "" {
 				ndkOpts.AndroidNdkRoot, err = android.GetAndroidNDKRoot(ndkOpts.AndroidNdkRoot)
 				if err != nil {
 					return err
@@ -211,28 +213,33 @@ func ProcessAndroidNDK(opts options.CLI, logger log.Logger) error {
 			}
 
 			logger.Debug(fmt.Sprintf("Extracting symbols from %s", file))
-			outputFile, err := android.Objcopy(objCopyPath, file, workingDir)
-			if err != nil {
-				return fmt.Errorf("objcopy failed for %s: %w", file, err)
+			var outputFile string
+			if ndkOpts.UseObjcopy {
+				outputFile, err = android.Objcopy(objCopyPath, file, workingDir)
+				if err != nil {
+					return fmt.Errorf("objcopy failed for %s: %w", file, err)
+				}
+			} else {
+				outputFile = filepath.Join(workingDir, utils.GetStringMD5(file))
+				err = elf.ExtractDebugSections(file, outputFile)
+				if err != nil {
+					return fmt.Errorf("extracting symbols from %s: %w", file, err)
+				}
 			}
 			logger.Debug(fmt.Sprintf("Extracted symbol files to %s", outputFile))
 			symbols[file] = outputFile
 		}
-
-		if err := android.UploadAndroidNdk(
-			symbols,
-			opts.ApiKey,
-			ndkOpts.ApplicationId,
-			ndkOpts.VersionName,
-			ndkOpts.VersionCode,
-			ndkOpts.ProjectRoot,
-			opts,
-			ndkOpts.Overwrite,
-			logger,
-		); err != nil {
-			return err
-		}
 	}
 
-	return nil
+	return android.UploadAndroidNdk(
+		symbols,
+		opts.ApiKey,
+		ndkOpts.ApplicationId,
+		ndkOpts.VersionName,
+		ndkOpts.VersionCode,
+		ndkOpts.ProjectRoot,
+		opts,
+		ndkOpts.Overwrite,
+		logger,
+	)
 }
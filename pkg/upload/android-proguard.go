@@ -3,7 +3,6 @@ package upload
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/android"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -33,25 +32,9 @@ func ProcessAndroidProguard(options options.CLI, logger log.Logger) error {
 	for _, path := range proguardOptions.Path {
 		if utils.IsDir(path) {
 			// Expect mapping files under <path>/app/build/outputs/mapping
-			mappingPath := filepath.Join(path, "app", "build", "outputs", "mapping")
-
-			if !utils.FileExists(mappingPath) {
-				return fmt.Errorf("unable to find the mapping directory in %s", path)
-			}
-
-			// Determine build variant if not specified
-			if proguardOptions.Variant == "" {
-				proguardOptions.Variant, err = android.GetVariantDirectory(mappingPath)
-				if err != nil {
-					return err
-				}
-			}
-
-			// Compose full path to mapping.txt for the variant
-			mappingFile = filepath.Join(mappingPath, proguardOptions.Variant, "mapping.txt")
-
-			if !utils.FileExists(mappingFile) {
-				return fmt.Errorf("unable to find mapping file in the specified project directory")
+			mappingFile, proguardOptions.Variant, err = android.FindMappingFile(path, proguardOptions.Variant)
+			if err != nil {
+				return err
 			}
 
 			// Attempt to locate AndroidManifest.xml for the variant if not set
@@ -88,6 +71,7 @@ func ProcessAndroidProguard(options options.CLI, logger log.Logger) error {
 						}
 					}
 					if options.ApiKey != "" {
+						log.AddSecret(options.ApiKey)
 						logger.Debug(fmt.Sprintf("Using %s as API key from AndroidManifest.xml", options.ApiKey))
 					}
 				}
@@ -181,7 +165,7 @@ func ProcessAndroidProguard(options options.CLI, logger log.Logger) error {
 		)
 
 		// Retry at base endpoint if 404 received
-		if err != nil && strings.Contains(err.Error(), "404 Not Found") {
+		if apiErr, ok := server.AsAPIError(err); ok && apiErr.IsNotFound() {
 			logger.Debug("Retrying upload for proguard at base endpoint")
 			err = server.ProcessFileRequest(
 				options.ApiKey,
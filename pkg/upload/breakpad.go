@@ -1,9 +1,12 @@
 package upload
 
 import (
+	"cmp"
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/bugsnag/bugsnag-cli/pkg/breakpad"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
@@ -31,11 +34,21 @@ func ProcessBreakpad(globalOptions options.CLI, logger log.Logger) error {
 	}
 
 	// Collect all .sym files from given paths
-	symFileList, err := utils.BuildFileList(breakpadOptions.Path)
+	fileList, err := utils.BuildFileList(breakpadOptions.Path)
 	if err != nil {
 		return err
 	}
 
+	var symFileList []string
+	for _, file := range fileList {
+		if strings.EqualFold(filepath.Ext(file), ".sym") {
+			symFileList = append(symFileList, file)
+		} else {
+			logger.Debug(fmt.Sprintf("Skipping %s - not a .sym file", file))
+			log.ReportFile(logger, log.FileResult{File: file, Status: log.FileSkipped, Reason: "not a .sym file"})
+		}
+	}
+
 	if len(symFileList) == 0 {
 		logger.Error("No .sym files found")
 		return nil
@@ -43,15 +56,43 @@ func ProcessBreakpad(globalOptions options.CLI, logger log.Logger) error {
 
 	logger.Debug(fmt.Sprintf("Uploading %d .sym files", len(symFileList)))
 
+	// The MODULE record is not needed if every field that is read from it is given as an override
+	hasOverrides := breakpadOptions.CpuArch != "" && breakpadOptions.CodeFile != "" && breakpadOptions.DebugFile != "" && breakpadOptions.DebugIdentifier != ""
+
+	var jobs []server.UploadJob
 	for _, file := range symFileList {
+		// Read the module details from the symbol file, using any options that were passed as overrides
+		module, err := breakpad.ReadModule(file)
+		if err != nil && hasOverrides {
+			logger.Debug(fmt.Sprintf("Using the module details given as options for %s: %s", file, err))
+			module = &breakpad.Module{}
+		} else if err != nil {
+			// Fail this file without stopping the uploads of the others
+			jobs = append(jobs, server.UploadJob{
+				Name: file,
+				Run: func(logger log.Logger) error {
+					log.ReportFile(logger, log.FileResult{File: file, Status: log.FileFailed, Error: err.Error()})
+					return err
+				},
+			})
+			continue
+		} else {
+			logger.Debug(fmt.Sprintf("Read MODULE %s %s %s %s from %s", module.Os, module.Arch, module.Id, module.Name, file))
+		}
+
+		codeFile := module.CodeFile
+		if codeFile == "" {
+			codeFile = module.Name
+		}
+
 		// Build form fields for the upload
 		formFields, err := utils.BuildBreakpadUploadOptions(
-			breakpadOptions.CpuArch,
-			breakpadOptions.CodeFile,
-			breakpadOptions.DebugFile,
-			breakpadOptions.DebugIdentifier,
+			cmp.Or(breakpadOptions.CpuArch, module.Arch),
+			cmp.Or(breakpadOptions.CodeFile, codeFile),
+			cmp.Or(breakpadOptions.DebugFile, module.Name),
+			cmp.Or(breakpadOptions.DebugIdentifier, module.Id),
 			breakpadOptions.ProductName,
-			breakpadOptions.OsName,
+			cmp.Or(breakpadOptions.OsName, module.Os),
 			breakpadOptions.VersionName,
 		)
 		if err != nil {
@@ -71,19 +112,21 @@ func ProcessBreakpad(globalOptions options.CLI, logger log.Logger) error {
 		)
 
 		// Send the file upload request to the Breakpad symbol endpoint
-		err = server.ProcessFileRequest(
-			apiKey,
-			"/breakpad-symbol"+queryParams,
-			formFields,
-			fileFieldData,
-			file,
-			globalOptions,
-			logger,
-		)
-		if err != nil {
-			return err
-		}
+		jobs = append(jobs, server.UploadJob{
+			Name: file,
+			Run: func(logger log.Logger) error {
+				return server.ProcessFileRequest(
+					apiKey,
+					"/breakpad-symbol"+queryParams,
+					formFields,
+					fileFieldData,
+					file,
+					globalOptions,
+					logger,
+				)
+			},
+		})
 	}
 
-	return nil
+	return server.RunUploadJobs(jobs, globalOptions.Concurrency, logger)
 }
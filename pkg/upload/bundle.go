@@ -0,0 +1,85 @@
+package upload
+
+import (
+	"fmt"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+)
+
+// ProcessPackage starts writing the upload requests of a package command to a symbol bundle.
+// The package command runs the same processing as the upload command, so its options are
+// copied to those of the upload command.
+//
+// Parameters:
+// - globalOptions: CLI options of the package command, which are updated for the upload command.
+// - logger: logger instance.
+//
+// Returns:
+// - *server.Packager: The packager, which must be closed once the command has run, or nil for a dry run.
+// - error: if the symbol bundle cannot be created.
+func ProcessPackage(globalOptions *options.CLI, logger log.Logger) (*server.Packager, error) {
+	globalOptions.Upload.UploadCommands = globalOptions.Package.UploadCommands
+	globalOptions.Upload.Exclude = globalOptions.Package.Exclude
+	globalOptions.Upload.NoCache = true
+
+	if globalOptions.DryRun {
+		logger.Info(fmt.Sprintf("(dryrun) Skipping writing the symbol bundle %s", globalOptions.Package.OutputFile))
+		return nil, nil
+	}
+
+	return server.StartPackage(globalOptions.Package.OutputFile)
+}
+
+// ProcessUploadBundle uploads the requests stored in a symbol bundle created by the package
+// command, exactly as they would have been sent when it was created. The API key is not
+// stored in the symbol bundle, so it must be given with --api-key, the environment or the
+// configuration file.
+//
+// Parameters:
+// - globalOptions: CLI options including the path to the symbol bundle.
+// - logger: logger instance.
+//
+// Returns:
+// - error: if no API key is given, or the symbol bundle cannot be read or any of its uploads fail.
+func ProcessUploadBundle(globalOptions options.CLI, logger log.Logger) error {
+	archivePath := globalOptions.Upload.Bundle.Archive
+	apiKey := globalOptions.ApiKey
+	if apiKey == "" {
+		return fmt.Errorf("missing api key, please specify using `--api-key`, the BUGSNAG_API_KEY environment variable or the configuration file")
+	}
+
+	reader, err := server.OpenPackage(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	logger.Info(fmt.Sprintf("Uploading %d request(s) from the symbol bundle %s created at %s", len(reader.Manifest.Requests), archivePath, reader.Manifest.CreatedAt.Local().Format("2006-01-02 15:04:05")))
+
+	var jobs []server.UploadJob
+	for _, request := range reader.Manifest.Requests {
+		fileFieldData, err := reader.FileFields(request)
+		if err != nil {
+			return err
+		}
+
+		jobs = append(jobs, server.UploadJob{
+			Name: request.FileName,
+			Run: func(logger log.Logger) error {
+				return server.ProcessFileRequest(
+					apiKey,
+					request.EndpointPath(apiKey),
+					request.Fields,
+					fileFieldData,
+					request.FileName,
+					globalOptions,
+					logger,
+				)
+			},
+		})
+	}
+
+	return server.RunUploadJobs(jobs, globalOptions.Concurrency, logger)
+}
@@ -4,12 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/elf"
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
@@ -86,7 +86,7 @@ func Dart(options options.CLI, logger log.Logger) error {
 			}
 
 			var buildId string
-			buildId, err = DwarfDumpUuid(file, iosAppPath, arch)
+			buildId, err = MachoUuid(file, iosAppPath, arch)
 			if err != nil {
 				return err
 			}
@@ -150,33 +150,16 @@ func GetIosAppPath(symbolFile string) (string, error) {
 	return "", fmt.Errorf("unable to find iOS app path, try adding --ios-app-path")
 }
 
-// DwarfDumpUuid - Gets the UUID/Build ID from the Dwarf debug info of a file for a given Arch
-func DwarfDumpUuid(symbolFile string, dwarfFile string, arch string) (string, error) {
-	dwarfDumpLocation, err := exec.LookPath("dwarfdump")
-
-	if err != nil {
-		dwarfDumpLocation, err = exec.LookPath("llvm-dwarfdump")
-
-		if err != nil {
-			return "", fmt.Errorf("unable to find dwarfdump on system: %w", err)
-		}
+// MachoUuid - Gets the UUID/Build ID of the app binary for a given Arch, read from its Mach-O load commands
+func MachoUuid(symbolFile string, appBinary string, arch string) (string, error) {
+	if !strings.Contains(symbolFile, arch) {
+		return "", fmt.Errorf("unable to find matching UUID")
 	}
 
-	uuidArray := make(map[string]string)
-	cmd := exec.Command(dwarfDumpLocation, "--uuid", dwarfFile, "--arch", arch)
-	output, _ := cmd.CombinedOutput()
-	outputArray := strings.Fields(string(output))
-
-	uuidArray[outputArray[2]] = outputArray[1]
-
-	for key, value := range uuidArray {
-		uuidArch := strings.Replace(key, "(", "", -1)
-		uuidArch = strings.Replace(uuidArch, ")", "", -1)
-
-		if strings.Contains(symbolFile, uuidArch) {
-			return value, nil
-		}
+	uuid, err := ios.GetMachoUuid(appBinary, arch)
+	if err != nil {
+		return "", fmt.Errorf("unable to find matching UUID: %w", err)
 	}
 
-	return "", fmt.Errorf("unable to find matching UUID")
+	return uuid, nil
 }
@@ -0,0 +1,356 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// BundlerManifest is the record of the files emitted by a bundler, used to pair each
+// bundle with its source map.
+type BundlerManifest struct {
+	// The bundler that wrote the manifest: webpack, vite or esbuild
+	Bundler string
+	// The public path that bundles are served from, if the bundler records it
+	PublicPath string
+	Bundles    []SourceMapBundle
+}
+
+// manifestFileNames are the names of bundler manifests looked for in the uploaded directory,
+// in order of preference.
+var manifestFileNames = []string{
+	filepath.Join(".vite", "manifest.json"),
+	"manifest.json",
+	"stats.json",
+	"meta.json",
+	"metafile.json",
+}
+
+// webpackStats is the part of a webpack stats.json file that describes emitted files.
+type webpackStats struct {
+	PublicPath string `json:"publicPath"`
+	OutputPath string `json:"outputPath"`
+	Assets     []struct {
+		Name string `json:"name"`
+		Info struct {
+			Related map[string]interface{} `json:"related"`
+		} `json:"info"`
+	} `json:"assets"`
+	Chunks []struct {
+		Files          []string `json:"files"`
+		AuxiliaryFiles []string `json:"auxiliaryFiles"`
+	} `json:"chunks"`
+	Children []webpackStats `json:"children"`
+}
+
+// viteManifestChunk is an entry in a Vite manifest.json file.
+type viteManifestChunk struct {
+	File string `json:"file"`
+}
+
+// esbuildMetafile is the part of an esbuild metafile that describes emitted files.
+type esbuildMetafile struct {
+	Outputs map[string]json.RawMessage `json:"outputs"`
+}
+
+// isJsFile reports whether a file emitted by a bundler is JavaScript, rather than CSS or
+// another asset that can also have a source map.
+func isJsFile(name string) bool {
+	name, _, _ = strings.Cut(name, "?")
+	for _, ext := range []string{".js", ".mjs", ".cjs"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindBundlerManifest looks for a bundler manifest in a directory.
+//
+// Parameters:
+// - dir: The directory that the bundler wrote its output to.
+//
+// Returns:
+// - string: The path to the manifest, or an empty string if none was found.
+func FindBundlerManifest(dir string) string {
+	for _, name := range manifestFileNames {
+		manifestPath := filepath.Join(dir, name)
+		if !utils.FileExists(manifestPath) || utils.IsDir(manifestPath) {
+			continue
+		}
+		// manifest.json is also the name of web app manifests, so check it is from a bundler
+		if _, err := ReadBundlerManifest(manifestPath, dir, ""); err == nil {
+			return manifestPath
+		}
+	}
+	return ""
+}
+
+// ReadBundlerManifest reads a webpack stats.json file, Vite manifest or esbuild metafile and
+// pairs each JavaScript file that the bundler emitted with its source map. The format is
+// detected from the contents of the file.
+//
+// Parameters:
+// - manifestPath: The path to the manifest.
+// - outputPath: The directory that the bundler wrote its output to.
+// - publicPath: The public path that bundles are served from, overriding the bundler's. May be empty.
+//
+// Returns:
+// - *BundlerManifest: The bundles in the manifest that have a source map.
+// - error: An error if the manifest cannot be read or is not in a known format.
+func ReadBundlerManifest(manifestPath string, outputPath string, publicPath string) (*BundlerManifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read bundler manifest %s: %w", manifestPath, err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal bundler manifest %s: %w", manifestPath, err)
+	}
+
+	manifestDir := filepath.Dir(manifestPath)
+	var manifest *BundlerManifest
+	switch {
+	case fields["outputs"] != nil && fields["inputs"] != nil:
+		manifest, err = readEsbuildMetafile(data, manifestDir, outputPath)
+	case fields["assets"] != nil || fields["chunks"] != nil || fields["children"] != nil:
+		manifest, err = readWebpackStats(data, manifestDir, outputPath)
+	default:
+		if filepath.Base(manifestDir) == ".vite" {
+			manifestDir = filepath.Dir(manifestDir)
+		}
+		manifest, err = readViteManifest(fields, manifestDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read bundler manifest %s: %w", manifestPath, err)
+	}
+
+	if publicPath != "" {
+		manifest.PublicPath = publicPath
+	}
+	return manifest, nil
+}
+
+// readWebpackStats pairs bundles with source maps using the source maps related to each
+// asset, or the files of each chunk for webpack 4, in a stats.json file and any child
+// compilations.
+func readWebpackStats(data []byte, manifestDir string, outputPath string) (*BundlerManifest, error) {
+	var stats webpackStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+
+	manifest := &BundlerManifest{Bundler: "webpack", PublicPath: stats.PublicPath}
+	var addStats func(stats webpackStats)
+	addStats = func(stats webpackStats) {
+		sourceMaps := map[string]string{}
+		for _, asset := range stats.Assets {
+			switch related := asset.Info.Related["sourceMap"].(type) {
+			case string:
+				sourceMaps[asset.Name] = related
+			case []interface{}:
+				if len(related) > 0 {
+					sourceMaps[asset.Name], _ = related[0].(string)
+				}
+			}
+		}
+		for _, chunk := range stats.Chunks {
+			files := map[string]bool{}
+			for _, file := range append(chunk.Files, chunk.AuxiliaryFiles...) {
+				files[file] = true
+			}
+			for _, file := range chunk.Files {
+				if sourceMaps[file] == "" && files[file+".map"] {
+					sourceMaps[file] = file + ".map"
+				}
+			}
+		}
+
+		baseDirs := []string{outputPath, stats.OutputPath, manifestDir}
+		for _, name := range slices.Sorted(maps.Keys(sourceMaps)) {
+			if isJsFile(name) && sourceMaps[name] != "" {
+				manifest.addBundle(name, sourceMaps[name], baseDirs)
+			}
+		}
+		for _, child := range stats.Children {
+			addStats(child)
+		}
+	}
+	addStats(stats)
+
+	if manifest.PublicPath == "" && len(stats.Children) > 0 {
+		manifest.PublicPath = stats.Children[0].PublicPath
+	}
+	return manifest, nil
+}
+
+// readViteManifest pairs each JavaScript chunk in a Vite manifest with the source map that
+// Vite writes next to it.
+func readViteManifest(fields map[string]json.RawMessage, outDir string) (*BundlerManifest, error) {
+	manifest := &BundlerManifest{Bundler: "vite"}
+	seen := map[string]bool{}
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		var chunk viteManifestChunk
+		if err := json.Unmarshal(fields[key], &chunk); err != nil || chunk.File == "" {
+			return nil, fmt.Errorf("not a webpack stats file, Vite manifest or esbuild metafile")
+		}
+		if !isJsFile(chunk.File) || seen[chunk.File] {
+			continue
+		}
+		seen[chunk.File] = true
+		manifest.addBundle(chunk.File, chunk.File+".map", []string{outDir})
+	}
+	return manifest, nil
+}
+
+// readEsbuildMetafile pairs each JavaScript output in an esbuild metafile with its source map
+// output. Output paths are relative to esbuild's working directory, so they are looked for
+// relative to the directory of the metafile and the current directory, and relative to the
+// uploaded directory without their leading directories.
+func readEsbuildMetafile(data []byte, manifestDir string, outputPath string) (*BundlerManifest, error) {
+	var metafile esbuildMetafile
+	if err := json.Unmarshal(data, &metafile); err != nil {
+		return nil, err
+	}
+
+	manifest := &BundlerManifest{Bundler: "esbuild"}
+	for _, name := range slices.Sorted(maps.Keys(metafile.Outputs)) {
+		if _, hasSourceMap := metafile.Outputs[name+".map"]; !isJsFile(name) || !hasSourceMap {
+			continue
+		}
+		bundle := manifest.addBundle(name, name+".map", []string{manifestDir, "."})
+		if bundle != nil {
+			continue
+		}
+		// Strip leading directories until the output is found in the uploaded directory
+		parts := strings.Split(filepath.ToSlash(name), "/")
+		for i := 1; i < len(parts) && bundle == nil; i++ {
+			relative := path.Join(parts[i:]...)
+			bundle = manifest.addBundle(relative, relative+".map", []string{outputPath})
+		}
+	}
+
+	// Public paths of esbuild outputs are relative to the uploaded directory
+	for i, bundle := range manifest.Bundles {
+		manifest.Bundles[i].UrlPath = ""
+		if relative, err := filepath.Rel(outputPath, bundle.BundlePath); err == nil && !strings.HasPrefix(relative, "..") {
+			manifest.Bundles[i].UrlPath = filepath.ToSlash(relative)
+		}
+	}
+	return manifest, nil
+}
+
+// addBundle adds a bundle and its source map, both relative to the first of the base
+// directories that contains them. Bundles that cannot be found are skipped.
+func (m *BundlerManifest) addBundle(bundleName string, sourceMapName string, baseDirs []string) *SourceMapBundle {
+	bundleName, _, _ = strings.Cut(bundleName, "?")
+	sourceMapName, _, _ = strings.Cut(sourceMapName, "?")
+	for _, baseDir := range baseDirs {
+		if baseDir == "" {
+			continue
+		}
+		bundlePath := filepath.Join(baseDir, filepath.FromSlash(bundleName))
+		sourceMapPath := filepath.Join(baseDir, filepath.FromSlash(sourceMapName))
+		if utils.FileExists(bundlePath) && utils.FileExists(sourceMapPath) {
+			m.Bundles = append(m.Bundles, SourceMapBundle{
+				BundlePath:    bundlePath,
+				SourceMapPath: sourceMapPath,
+				UrlPath:       strings.TrimPrefix(filepath.ToSlash(bundleName), "/"),
+			})
+			return &m.Bundles[len(m.Bundles)-1]
+		}
+	}
+	return nil
+}
+
+// PublicUrl returns the URL that bundles in the manifest are served from, if the bundler
+// recorded an absolute URL as its public path.
+//
+// Returns:
+// - string: The public URL ending with a slash, or an empty string if it is not known.
+func (m *BundlerManifest) PublicUrl() string {
+	publicPath := m.PublicPath
+	if !strings.Contains(publicPath, "://") && !strings.HasPrefix(publicPath, "//") {
+		return ""
+	}
+	if !strings.HasSuffix(publicPath, "/") {
+		publicPath += "/"
+	}
+	return publicPath
+}
+
+// JoinPublicPath adds the public path to the URL path of each bundle if it is relative to the
+// host that bundles are served from, such as /static/js/.
+func (m *BundlerManifest) JoinPublicPath() {
+	relative := relativePublicPath(m.PublicPath)
+	if relative == "" {
+		return
+	}
+	for i, bundle := range m.Bundles {
+		if bundle.UrlPath != "" {
+			m.Bundles[i].UrlPath = path.Join(relative, bundle.UrlPath)
+		}
+	}
+}
+
+// relativePublicPath returns a public path that is relative to the host that bundles are
+// served from, without leading or trailing slashes. An empty string is returned for absolute
+// URLs, which are used as the base URL instead, and for webpack's automatic public path.
+func relativePublicPath(publicPath string) string {
+	if publicPath == "auto" || strings.Contains(publicPath, "://") || strings.HasPrefix(publicPath, "//") {
+		return ""
+	}
+	publicPath = strings.Trim(strings.TrimPrefix(publicPath, "./"), "/")
+	if publicPath == "." {
+		return ""
+	}
+	return publicPath
+}
+
+// resolveManifestBundles reads the bundler manifest given on the command line, or found in
+// the uploaded directory, and returns the bundles that it pairs with source maps. A relative
+// public path is added to the URL path of each bundle if it is given by --public-path, or if
+// the manifest is given by --manifest or --base-url is not set, so that a manifest found
+// automatically does not change the URLs given by --base-url.
+//
+// Parameters:
+// - manifestPath: The path given by --manifest, which may be empty.
+// - outputPath: The uploaded directory.
+// - publicPath: The path given by --public-path, which may be empty.
+// - baseUrl: The URL given by --base-url, which may be empty.
+// - logger: logger instance.
+//
+// Returns:
+// - *BundlerManifest: The manifest, or nil if there is none.
+// - error: An error if the manifest given on the command line cannot be read.
+func resolveManifestBundles(manifestPath string, outputPath string, publicPath string, baseUrl string, logger log.Logger) (*BundlerManifest, error) {
+	joinPublicPath := publicPath != "" || manifestPath != "" || baseUrl == ""
+	if manifestPath == "" {
+		if !utils.IsDir(outputPath) {
+			return nil, nil
+		}
+		manifestPath = FindBundlerManifest(outputPath)
+		if manifestPath == "" {
+			return nil, nil
+		}
+	}
+
+	manifest, err := ReadBundlerManifest(manifestPath, outputPath, publicPath)
+	if err != nil {
+		return nil, err
+	}
+	if joinPublicPath {
+		manifest.JoinPublicPath()
+	}
+	logger.Info(fmt.Sprintf("Found %d bundle(s) with source maps in the %s manifest %s", len(manifest.Bundles), manifest.Bundler, manifestPath))
+	return manifest, nil
+}
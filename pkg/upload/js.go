@@ -13,6 +13,7 @@ import (
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
 )
 
@@ -20,6 +21,8 @@ import (
 type SourceMapBundle struct {
 	BundlePath    string
 	SourceMapPath string
+	// The path of the bundle relative to its public path, if it was found in a bundler manifest
+	UrlPath string
 }
 
 // Precompiled regexp for matching sourceMappingURL comments.
@@ -159,7 +162,7 @@ func ExtractSourceMappingURL(filePath string, logger log.Logger) (string, error)
 	source := string(data)
 
 	// Split by line terminators as defined in ECMA-426: CR+LF, LF, CR, U+2028, U+2029
-	lines := splitLines(source)
+	lines := SplitLines(source)
 
 	// Process lines in reverse order
 	for i := len(lines) - 1; i >= 0; i-- {
@@ -217,9 +220,9 @@ func ExtractSourceMappingURL(filePath string, logger log.Logger) (string, error)
 	return "", nil
 }
 
-// splitLines splits a string by JavaScript line terminators as defined in ECMA-426.
+// SplitLines splits a string by JavaScript line terminators as defined in ECMA-426.
 // Line terminators: CR+LF (\r\n), LF (\n), CR (\r), U+2028 (Line Separator), U+2029 (Paragraph Separator)
-func splitLines(source string) []string {
+func SplitLines(source string) []string {
 	// Replace all line terminators with \n, then split
 	source = strings.ReplaceAll(source, "\r\n", "\n")
 	source = strings.ReplaceAll(source, "\r", "\n")
@@ -320,6 +323,24 @@ func ResolveBundlePaths(bundlePath string, outputPath string, logger log.Logger)
 	return bundlePaths, nil
 }
 
+// ResolveSourceMapArgument works out where to find source maps for a path given on the
+// command line. If the path is a .map file and no source map has been given, the path is
+// treated as the source map itself, and its directory is searched for the bundle.
+//
+// Parameters:
+// - path: the path given on the command line.
+// - sourceMapPath: user-specified source map path (can be empty).
+//
+// Returns:
+// - the source map path to pass to ResolveSourceMapPaths.
+// - the directory to pass to ResolveSourceMapPaths as the output path.
+func ResolveSourceMapArgument(path string, sourceMapPath string) (string, string) {
+	if sourceMapPath == "" && strings.HasSuffix(path, ".map") && utils.FileExists(path) && !utils.IsDir(path) {
+		return path, filepath.Dir(path)
+	}
+	return sourceMapPath, path
+}
+
 // ResolveSourceMapPaths attempts to find source map(s) by scanning bundle files
 // for sourceMappingURL comments.
 //
@@ -461,6 +482,44 @@ func ReadSourceMap(path string, logger log.Logger) (map[string]interface{}, erro
 	return sourceMapContents, nil
 }
 
+// ResolveSourcePath resolves a path in the sources field of a source map to the path of
+// the source file on disk.
+//
+// Parameters:
+// - sourcePath: the path from the sources field.
+// - sourceMapPath: path to the source map file, which relative paths are resolved against.
+//
+// Returns:
+// - the absolute path to the source file.
+// - false if the source is a virtual webpack file that does not exist on disk.
+func ResolveSourcePath(sourcePath string, sourceMapPath string) (string, bool) {
+	// Handle the default webpack prefix in accordance with https://webpack.js.org/configuration/output/#outputdevtoolmodulefilenametemplate
+	sourcePath, isWebpack := strings.CutPrefix(sourcePath, "webpack://")
+	if isWebpack {
+		// Remove the namespace
+		firstSlash := strings.Index(sourcePath, "/")
+		if firstSlash != -1 && firstSlash+1 < len(sourcePath) {
+			sourcePath = sourcePath[firstSlash+1:]
+		}
+
+		// Skip virtual webpack files
+		if strings.Contains(sourcePath, "webpack/") {
+			return "", false
+		}
+
+		// Remove any loaders
+		questionMark := strings.LastIndex(sourcePath, "?")
+		if questionMark-1 > 0 {
+			sourcePath = sourcePath[:questionMark-1]
+		}
+	}
+	if !filepath.IsAbs(sourcePath) {
+		// Resolve the path relative to the source map
+		sourcePath, _ = filepath.Abs(filepath.Join(filepath.Dir(sourceMapPath), sourcePath))
+	}
+	return sourcePath, true
+}
+
 // addSourcesContent adds the sourcesContent to a source map section if missing.
 //
 // Returns:
@@ -495,30 +554,10 @@ func addSourcesContent(section map[string]interface{}, sourceMapPath string, log
 			sourcesContent = append(sourcesContent, nil)
 			continue
 		}
-		// Handle the default webpack prefix in accordance with https://webpack.js.org/configuration/output/#outputdevtoolmodulefilenametemplate
-		sourcePath, isWebpack := strings.CutPrefix(sourcePath, "webpack://")
-		if isWebpack {
-			// Remove the namespace
-			firstSlash := strings.Index(sourcePath, "/")
-			if firstSlash != -1 && firstSlash+1 < len(sourcePath) {
-				sourcePath = sourcePath[firstSlash+1:]
-			}
-
-			// Skip virtual webpack files
-			if strings.Contains(sourcePath, "webpack/") {
-				sourcesContent = append(sourcesContent, nil)
-				continue
-			}
-
-			// Remove any loaders
-			questionMark := strings.LastIndex(sourcePath, "?")
-			if questionMark-1 > 0 {
-				sourcePath = sourcePath[:questionMark-1]
-			}
-		}
-		if !filepath.IsAbs(sourcePath) {
-			// Resolve the path relative to the source map
-			sourcePath, _ = filepath.Abs(filepath.Join(filepath.Dir(sourceMapPath), sourcePath))
+		sourcePath, isFile := ResolveSourcePath(sourcePath, sourceMapPath)
+		if !isFile {
+			sourcesContent = append(sourcesContent, nil)
+			continue
 		}
 		logger.Debug(fmt.Sprintf("Attempting to read the source %s.", sourcePath))
 		content, err := os.ReadFile(sourcePath)
@@ -556,6 +595,76 @@ func AddSources(sourceMapContents map[string]interface{}, sourceMapPath string,
 	return false
 }
 
+// readSourceMapForUpload reads a source map to upload, composing it with the source maps of
+// earlier build steps, or flattening it if it is an index map, when requested.
+//
+// Parameters:
+// - sourceMapPath: path to source map file.
+// - composeWith: source maps of earlier build steps, latest first.
+// - flatten: whether to convert an index map into a regular source map.
+// - logger: logger instance.
+//
+// Returns:
+// - decoded source map.
+// - true if the source map differs from the file.
+// - error if the source map cannot be read or composed.
+func readSourceMapForUpload(sourceMapPath string, composeWith []string, flatten bool, logger log.Logger) (map[string]interface{}, bool, error) {
+	if len(composeWith) > 0 {
+		logger.Info(fmt.Sprintf("Composing %s with %s", sourceMapPath, strings.Join(composeWith, ", ")))
+		composed, err := sourcemap.ComposeFiles(append([]string{sourceMapPath}, composeWith...))
+		return composed, true, err
+	}
+
+	sourceMapContents, err := ReadSourceMap(sourceMapPath, logger)
+	if err != nil || !flatten {
+		return sourceMapContents, false, err
+	}
+	if _, isIndexMap := sourceMapContents["sections"]; !isIndexMap {
+		return sourceMapContents, false, nil
+	}
+
+	logger.Info(fmt.Sprintf("Flattening index map %s", sourceMapPath))
+	flattened, err := sourcemap.Flatten(sourceMapContents)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot flatten index map %s: %w", sourceMapPath, err)
+	}
+	return flattened, true, nil
+}
+
+// ResolveDebugId returns the debug ID written into a bundle or its source map by
+// `inject-debug-ids` or a bundler, so that the upload can be matched to events by the
+// debug ID of the bundle rather than by its URL and app version.
+//
+// Parameters:
+// - sourceMapContents: decoded source map.
+// - bundlePath: path to the bundle, or an empty string if there is none.
+// - logger: logger instance.
+//
+// Returns:
+// - The debug ID, or an empty string if neither file has one.
+func ResolveDebugId(sourceMapContents map[string]interface{}, bundlePath string, logger log.Logger) string {
+	sourceMapDebugId := sourcemap.SourceMapDebugId(sourceMapContents)
+	if bundlePath == "" {
+		return sourceMapDebugId
+	}
+
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Unable to read %s to find its debug ID: %s", bundlePath, err))
+		return sourceMapDebugId
+	}
+
+	bundleDebugId := sourcemap.BundleDebugId(bundle)
+	if bundleDebugId == "" {
+		return sourceMapDebugId
+	}
+	if sourceMapDebugId != "" && sourceMapDebugId != bundleDebugId {
+		// Events are reported with the debug ID of the bundle
+		logger.Warn(fmt.Sprintf("The debug ID of %s (%s) does not match its source map (%s), using the bundle's debug ID", bundlePath, bundleDebugId, sourceMapDebugId))
+	}
+	return bundleDebugId
+}
+
 // uploadSingleSourceMap uploads a single source map.
 //
 // Parameters:
@@ -571,7 +680,7 @@ func AddSources(sourceMapContents map[string]interface{}, sourceMapPath string,
 // Returns:
 // - error if upload fails.
 func uploadSingleSourceMap(sourceMapPath string, bundlePath string, bundleUrl string, versionName string, codeBundleId string, projectRoot string, options options.CLI, logger log.Logger) error {
-	sourceMapContents, err := ReadSourceMap(sourceMapPath, logger)
+	sourceMapContents, composed, err := readSourceMapForUpload(sourceMapPath, options.Upload.Js.ComposeSourceMap, options.Upload.Js.FlattenIndexMaps, logger)
 	if err != nil {
 		return err
 	}
@@ -579,8 +688,10 @@ func uploadSingleSourceMap(sourceMapPath string, bundlePath string, bundleUrl st
 	var sourceMapFile server.FileField
 
 	sourceMapModified := AddSources(sourceMapContents, sourceMapPath, logger)
-	if sourceMapModified {
-		logger.Info(fmt.Sprintf("Added sources content to source map from %s", sourceMapPath))
+	if sourceMapModified || composed {
+		if sourceMapModified {
+			logger.Info(fmt.Sprintf("Added sources content to source map from %s", sourceMapPath))
+		}
 		encodedSourceMap, err := json.Marshal(sourceMapContents)
 		if err != nil {
 			return fmt.Errorf("failed generate valid source map JSON with original sources added: %s", err.Error())
@@ -598,6 +709,11 @@ func uploadSingleSourceMap(sourceMapPath string, bundlePath string, bundleUrl st
 		return fmt.Errorf("failed to build upload options: %s", err.Error())
 	}
 
+	if debugId := ResolveDebugId(sourceMapContents, bundlePath, logger); debugId != "" {
+		logger.Debug(fmt.Sprintf("Using debug ID %s", debugId))
+		uploadOptions["debugId"] = debugId
+	}
+
 	fileFieldData := make(map[string]server.FileField)
 	fileFieldData["sourceMap"] = sourceMapFile
 	fileFieldData["minifiedFile"] = server.LocalFile(bundlePath)
@@ -631,15 +747,9 @@ func ProcessJs(options options.CLI, logger log.Logger) error {
 	jsOptions := options.Upload.Js
 	for _, path := range jsOptions.Path {
 
-		outputPath := path
-
-		// If the path is a .map file and no explicit --source-map is set,
-		// treat the path as the source map itself
-		if jsOptions.SourceMap == "" && strings.HasSuffix(path, ".map") && utils.FileExists(path) && !utils.IsDir(path) {
-			jsOptions.SourceMap = path
-			// For a direct .map file, use its directory as the output path
-			outputPath = filepath.Dir(path)
-		}
+		// If the path is a .map file, treat the path as the source map itself
+		var outputPath string
+		jsOptions.SourceMap, outputPath = ResolveSourceMapArgument(path, jsOptions.SourceMap)
 
 		// Set a default value for projectRoot if it's not defined
 		jsOptions.ProjectRoot = resolveProjectRoot(jsOptions.ProjectRoot, path)
@@ -647,10 +757,27 @@ func ProcessJs(options options.CLI, logger log.Logger) error {
 
 		jsOptions.VersionName = resolveVersion(jsOptions.VersionName, path, logger)
 
-		// Resolve source map and bundle pairs
-		sourceMapBundles, err := ResolveSourceMapPaths(jsOptions.SourceMap, jsOptions.Bundle, outputPath, logger)
-		if err != nil {
-			return err
+		// Resolve source map and bundle pairs, preferring the bundler's own record of them
+		var manifest *BundlerManifest
+		var err error
+		if jsOptions.SourceMap == "" && jsOptions.Bundle == "" {
+			manifest, err = resolveManifestBundles(jsOptions.Manifest, outputPath, jsOptions.PublicPath, jsOptions.BaseUrl, logger)
+			if err != nil {
+				return err
+			}
+		}
+
+		var sourceMapBundles []SourceMapBundle
+		if manifest != nil && len(manifest.Bundles) > 0 {
+			sourceMapBundles = manifest.Bundles
+		} else {
+			if manifest != nil {
+				logger.Warn("No bundles with source maps were found in the bundler manifest, searching for source maps instead")
+			}
+			sourceMapBundles, err = ResolveSourceMapPaths(jsOptions.SourceMap, jsOptions.Bundle, outputPath, logger)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Check that we found at least one source map
@@ -667,6 +794,11 @@ func ProcessJs(options options.CLI, logger log.Logger) error {
 		if isFile && jsOptions.BaseUrl != "" {
 			return fmt.Errorf("`--base-url` must not be set when uploading a file")
 		}
+		// Bundlers that record an absolute public path give the base URL themselves
+		if !isFile && jsOptions.BaseUrl == "" && manifest != nil && manifest.PublicUrl() != "" {
+			jsOptions.BaseUrl = manifest.PublicUrl()
+			logger.Debug(fmt.Sprintf("Using the public path %s from the %s manifest as the base URL", jsOptions.BaseUrl, manifest.Bundler))
+		}
 		if !isFile && jsOptions.BaseUrl == "" {
 			return fmt.Errorf("`--base-url` must be set when uploading from a directory")
 		}
@@ -683,6 +815,10 @@ func ProcessJs(options options.CLI, logger log.Logger) error {
 			var bundleUrl string
 			if jsOptions.BundleUrl != "" {
 				bundleUrl = jsOptions.BundleUrl
+			} else if bundle.UrlPath != "" {
+				// Bundler manifests give the path of the bundle relative to its public path
+				bundleUrl = jsOptions.BaseUrl + bundle.UrlPath
+				logger.Debug(fmt.Sprintf("Generated URL %s using the base URL %s", bundleUrl, jsOptions.BaseUrl))
 			} else {
 				// For directory uploads, add the relative path of the bundle to the base URL
 				bundleUrl = jsOptions.BaseUrl + strings.TrimPrefix(strings.TrimPrefix(bundle.BundlePath, path), "/")
@@ -104,10 +104,12 @@ func ProcessLinux(opts options.CLI, logger log.Logger) error {
 					logger.Debug(fmt.Sprintf("Found symbol file: %s", file))
 				} else {
 					logger.Debug(fmt.Sprintf("%s is not a valid symbol file.", file))
+					log.ReportFile(logger, log.FileResult{File: file, Status: log.FileSkipped, Reason: "not a valid symbol file"})
 				}
 			} else {
 				// Skip files not matching the common suffixes
 				logger.Debug(fmt.Sprintf("Skipping non-symbol file: %s", file))
+				log.ReportFile(logger, log.FileResult{File: file, Status: log.FileSkipped, Reason: "not a symbol file"})
 			}
 		}
 
@@ -120,10 +122,18 @@ func ProcessLinux(opts options.CLI, logger log.Logger) error {
 			return nil
 		}
 
+		var jobs []server.UploadJob
 		for _, file := range soFileList {
-			if err := uploadSymbolFile(file, linuxOpts, opts, logger); err != nil {
-				return err
-			}
+			jobs = append(jobs, server.UploadJob{
+				Name: file,
+				Run: func(logger log.Logger) error {
+					return uploadSymbolFile(file, linuxOpts, opts, logger)
+				},
+			})
+		}
+
+		if err := server.RunUploadJobs(jobs, opts.Concurrency, logger); err != nil {
+			return err
 		}
 	}
 	return nil
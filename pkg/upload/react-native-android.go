@@ -135,6 +135,7 @@ func ProcessReactNativeAndroid(globalOptions options.CLI, logger log.Logger) err
 							break
 						}
 					}
+					log.AddSecret(globalOptions.ApiKey)
 					logger.Debug(fmt.Sprintf("Using %s as API key from AndroidManifest.xml", globalOptions.ApiKey))
 				}
 
@@ -153,17 +154,24 @@ func ProcessReactNativeAndroid(globalOptions options.CLI, logger log.Logger) err
 			}
 		}
 
+		// Hermes builds keep the packager and compiler source maps in the intermediates directory
+		hermesSourceMapDirPath := filepath.Join(appBuildPath, "intermediates", "sourcemaps", "react", androidOptions.Android.Variant)
+		packagerMap, compilerMap := FindHermesSourceMaps(androidOptions.ReactNative.Bundle, androidOptions.ReactNative.SourceMap, []string{hermesSourceMapDirPath})
+		sourceMap, composeSourceMap := resolveHermesSourceMaps(androidOptions.ReactNative.Bundle, androidOptions.ReactNative.SourceMap, androidOptions.ReactNative.ComposeSourceMap, packagerMap, compilerMap, logger)
+
 		// Set the options for the source map upload
 		globalOptions.Upload.ReactNativeSourcemaps = options.ReactNativeSourcemaps{
-			VersionName:  androidOptions.ReactNative.VersionName,
-			VersionCode:  androidOptions.Android.VersionCode,
-			CodeBundleId: androidOptions.ReactNative.CodeBundleId,
-			Dev:          androidOptions.ReactNative.Dev,
-			ProjectRoot:  androidOptions.ProjectRoot,
-			SourceMap:    androidOptions.ReactNative.SourceMap,
-			Bundle:       androidOptions.ReactNative.Bundle,
-			Overwrite:    androidOptions.Overwrite,
-			Platform:     "android",
+			VersionName:      androidOptions.ReactNative.VersionName,
+			VersionCode:      androidOptions.Android.VersionCode,
+			CodeBundleId:     androidOptions.ReactNative.CodeBundleId,
+			Dev:              androidOptions.ReactNative.Dev,
+			ProjectRoot:      androidOptions.ProjectRoot,
+			SourceMap:        sourceMap,
+			Bundle:           androidOptions.ReactNative.Bundle,
+			Overwrite:        androidOptions.Overwrite,
+			Platform:         "android",
+			FlattenIndexMaps: androidOptions.ReactNative.FlattenIndexMaps,
+			ComposeSourceMap: composeSourceMap,
 		}
 
 		err = ProcessReactNativeSourcemaps(globalOptions, logger)
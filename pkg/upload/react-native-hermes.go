@@ -0,0 +1,149 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// hermesMagic is the magic number at the start of a Hermes bytecode bundle.
+var hermesMagic = []byte{0xc6, 0x1f, 0xbc, 0x03, 0xc1, 0x03, 0x19, 0x1f}
+
+// IsHermesBundle reports whether a bundle has been compiled to Hermes bytecode.
+//
+// Parameters:
+// - bundlePath: path to the bundle.
+//
+// Returns:
+// - bool: true if the bundle starts with the Hermes bytecode magic number.
+func IsHermesBundle(bundlePath string) bool {
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, len(hermesMagic))
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, hermesMagic)
+}
+
+// FindHermesSourceMaps finds the source maps written by the React Native build for a
+// Hermes bundle: the packager map, from the JavaScript bundle to the original sources, and
+// the compiler map, from the Hermes bytecode to the JavaScript bundle. They are named after
+// the bundle or source map with a `.packager.map` or `.compiler.map` extension, and are
+// looked for in the given directories and then next to the source map and the bundle.
+//
+// Parameters:
+// - bundlePath: path to the bundle.
+// - sourceMapPath: path to the source map, which may be empty.
+// - searchDirs: directories to look in before those of the source map and bundle.
+//
+// Returns:
+// - string: path to the packager map, or empty if it was not found.
+// - string: path to the compiler map, or empty if it was not found.
+func FindHermesSourceMaps(bundlePath string, sourceMapPath string, searchDirs []string) (string, string) {
+	baseNames := []string{filepath.Base(bundlePath)}
+	if sourceMapPath != "" {
+		baseNames = append(baseNames, strings.TrimSuffix(filepath.Base(sourceMapPath), ".map"))
+		searchDirs = append(searchDirs, filepath.Dir(sourceMapPath))
+	}
+	searchDirs = append(searchDirs, filepath.Dir(bundlePath))
+
+	find := func(extension string) string {
+		for _, dir := range searchDirs {
+			for _, baseName := range baseNames {
+				path := filepath.Join(dir, baseName+extension)
+				if utils.FileExists(path) && !utils.IsDir(path) {
+					return path
+				}
+			}
+		}
+		return ""
+	}
+
+	return find(".packager.map"), find(".compiler.map")
+}
+
+// FindXcodeHermesSourceMaps finds the packager and compiler source maps written by the
+// react-native-xcode.sh build phase for a Hermes bundle. As well as the names looked for by
+// FindHermesSourceMaps, the build phase writes the packager map to CONFIGURATION_BUILD_DIR
+// with the same name as the final source map, and the compiler map next to the bundle with
+// a `.map` extension, so that both are named main.jsbundle.map by default.
+//
+// Parameters:
+// - bundlePath: path to the bundle.
+// - sourceMapPath: path to the source map, which may be empty.
+// - configurationBuildDir: the Xcode build products directory, which may be empty.
+//
+// Returns:
+// - string: path to the packager map, or empty if it was not found.
+// - string: path to the compiler map, or empty if it was not found.
+func FindXcodeHermesSourceMaps(bundlePath string, sourceMapPath string, configurationBuildDir string) (string, string) {
+	var searchDirs []string
+	if configurationBuildDir != "" {
+		searchDirs = append(searchDirs, configurationBuildDir)
+	}
+	packagerMap, compilerMap := FindHermesSourceMaps(bundlePath, sourceMapPath, searchDirs)
+	if packagerMap != "" && compilerMap != "" || configurationBuildDir == "" {
+		return packagerMap, compilerMap
+	}
+
+	sourceMapName := filepath.Base(bundlePath) + ".map"
+	if sourceMapPath != "" {
+		sourceMapName = filepath.Base(sourceMapPath)
+	}
+	packagerMap = filepath.Join(configurationBuildDir, sourceMapName)
+	compilerMap = bundlePath + ".map"
+
+	// The maps must be separate files from each other and from the final source map, which
+	// overwrites the packager map if it is written to the same place
+	isFile := func(path string) bool {
+		return utils.FileExists(path) && !utils.IsDir(path)
+	}
+	if !isFile(packagerMap) || !isFile(compilerMap) ||
+		filepath.Clean(packagerMap) == filepath.Clean(compilerMap) ||
+		(sourceMapPath != "" && filepath.Clean(packagerMap) == filepath.Clean(sourceMapPath)) {
+		return "", ""
+	}
+	return packagerMap, compilerMap
+}
+
+// resolveHermesSourceMaps returns the source map to upload for a bundle and the source maps
+// to compose it with. If the bundle is Hermes bytecode and the packager and compiler maps
+// were found, the compiler map is composed with the packager map so that the uploaded
+// source map maps the bytecode to the original sources. Otherwise, or if source maps to
+// compose with were given, they are returned unchanged.
+//
+// Parameters:
+// - bundlePath: path to the bundle.
+// - sourceMapPath: path to the source map.
+// - composeSourceMap: source maps to compose with that were given on the command line.
+// - packagerMap: path to the packager map, or empty if it was not found.
+// - compilerMap: path to the compiler map, or empty if it was not found.
+// - logger: logger instance.
+//
+// Returns:
+// - string: path to the source map to upload.
+// - []string: paths to the source maps to compose it with.
+func resolveHermesSourceMaps(bundlePath string, sourceMapPath string, composeSourceMap []string, packagerMap string, compilerMap string, logger log.Logger) (string, []string) {
+	if len(composeSourceMap) > 0 || !IsHermesBundle(bundlePath) {
+		return sourceMapPath, composeSourceMap
+	}
+
+	if packagerMap == "" || compilerMap == "" {
+		logger.Warn(fmt.Sprintf("%s is a Hermes bundle but its packager and compiler source maps were not found, uploading %s as it is. If it only maps the bytecode to the JavaScript bundle, give the packager source map with --compose-source-map", bundlePath, sourceMapPath))
+		return sourceMapPath, composeSourceMap
+	}
+
+	logger.Info(fmt.Sprintf("Composing the Hermes source maps %s and %s", compilerMap, packagerMap))
+	return compilerMap, []string{packagerMap}
+}
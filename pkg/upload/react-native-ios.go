@@ -196,22 +196,29 @@ func ProcessReactNativeIos(globalOptions options.CLI, logger log.Logger) error {
 
 		if globalOptions.ApiKey == "" {
 			globalOptions.ApiKey = plistData.BugsnagProjectDetails.ApiKey
+			log.AddSecret(globalOptions.ApiKey)
 			logger.Debug(fmt.Sprintf("Using API key from Info.plist: %s", globalOptions.ApiKey))
 		}
 
 	}
 
+	// Hermes builds leave the packager source map in the Xcode build products directory
+	packagerMap, compilerMap := FindXcodeHermesSourceMaps(iosOptions.ReactNative.Bundle, iosOptions.ReactNative.SourceMap, os.Getenv("CONFIGURATION_BUILD_DIR"))
+	sourceMap, composeSourceMap := resolveHermesSourceMaps(iosOptions.ReactNative.Bundle, iosOptions.ReactNative.SourceMap, iosOptions.ReactNative.ComposeSourceMap, packagerMap, compilerMap, logger)
+
 	// Set the options for the source map upload
 	globalOptions.Upload.ReactNativeSourcemaps = options.ReactNativeSourcemaps{
-		VersionName:   iosOptions.ReactNative.VersionName,
-		BundleVersion: iosOptions.Ios.BundleVersion,
-		CodeBundleId:  iosOptions.ReactNative.CodeBundleId,
-		Dev:           iosOptions.ReactNative.Dev,
-		ProjectRoot:   iosOptions.ProjectRoot,
-		SourceMap:     iosOptions.ReactNative.SourceMap,
-		Bundle:        iosOptions.ReactNative.Bundle,
-		Overwrite:     iosOptions.Overwrite,
-		Platform:      "ios",
+		VersionName:      iosOptions.ReactNative.VersionName,
+		BundleVersion:    iosOptions.Ios.BundleVersion,
+		CodeBundleId:     iosOptions.ReactNative.CodeBundleId,
+		Dev:              iosOptions.ReactNative.Dev,
+		ProjectRoot:      iosOptions.ProjectRoot,
+		SourceMap:        sourceMap,
+		Bundle:           iosOptions.ReactNative.Bundle,
+		Overwrite:        iosOptions.Overwrite,
+		Platform:         "ios",
+		FlattenIndexMaps: iosOptions.ReactNative.FlattenIndexMaps,
+		ComposeSourceMap: composeSourceMap,
 	}
 
 	err = ProcessReactNativeSourcemaps(globalOptions, logger)
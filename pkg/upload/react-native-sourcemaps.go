@@ -1,6 +1,7 @@
 package upload
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -22,6 +23,8 @@ import (
 // Behavior:
 //   - Validates presence of required identifiers (versionName, versionCode/bundleVersion, codeBundleId).
 //   - Builds metadata for either iOS or Android React Native builds.
+//   - Composes or flattens the source map first if requested.
+//   - Includes the debug ID of the bundle and source map, if they have one.
 //   - Uploads both the source map and the JS bundle to the Bugsnag /react-native-source-map endpoint.
 //
 // Returns:
@@ -85,16 +88,39 @@ func ProcessReactNativeSourcemaps(globalOptions options.CLI, logger log.Logger)
 		uploadOpts["overwrite"] = "true"
 	}
 
+	// Compose or flatten the source map if requested
+	var sourceMapFile server.FileField = server.LocalFile(reactNativeOpts.SourceMap)
+	// The source map is otherwise uploaded as-is, even if it cannot be read here
+	sourceMapContents, composed, err := readSourceMapForUpload(reactNativeOpts.SourceMap, reactNativeOpts.ComposeSourceMap, reactNativeOpts.FlattenIndexMaps, logger)
+	if err != nil && (len(reactNativeOpts.ComposeSourceMap) > 0 || reactNativeOpts.FlattenIndexMaps) {
+		return err
+	}
+	if composed {
+		encodedSourceMap, err := json.Marshal(sourceMapContents)
+		if err != nil {
+			return fmt.Errorf("failed to encode the composed source map: %w", err)
+		}
+		sourceMapFile = server.InMemoryFile{Path: reactNativeOpts.SourceMap, Data: encodedSourceMap}
+	}
+
+	// Debug ID written into the bundle and source map by inject-debug-ids
+	if err == nil {
+		if debugId := ResolveDebugId(sourceMapContents, reactNativeOpts.Bundle, logger); debugId != "" {
+			logger.Debug(fmt.Sprintf("Using debug ID %s", debugId))
+			uploadOpts["debugId"] = debugId
+		}
+	}
+
 	// Prepare upload files
 	fileFields := map[string]server.FileField{
-		"sourceMap": server.LocalFile(reactNativeOpts.SourceMap),
+		"sourceMap": sourceMapFile,
 		"bundle":    server.LocalFile(reactNativeOpts.Bundle),
 	}
 
 	logger.Info(fmt.Sprintf("Uploading React Native source map for platform: %s", reactNativeOpts.Platform))
 
 	// Perform upload request
-	err := server.ProcessFileRequest(
+	err = server.ProcessFileRequest(
 		globalOptions.ApiKey,
 		"/react-native-source-map",
 		uploadOpts,
@@ -162,23 +162,23 @@ func ProcessUnityAndroid(globalOptions options.CLI, logger log.Logger) error {
 			}
 		}
 
-		for originalFile, symbolPath := range symbolFileList {
-			err = android.UploadAndroidNdk(
-				map[string]string{originalFile: symbolPath},
-				manifestData["apiKey"],
-				manifestData["applicationId"],
-				manifestData["versionName"],
-				manifestData["versionCode"],
-				unityOptions.ProjectRoot,
-				globalOptions,
-				unityOptions.Overwrite,
-				logger,
-			)
+		err = android.UploadAndroidNdk(
+			symbolFileList,
+			manifestData["apiKey"],
+			manifestData["applicationId"],
+			manifestData["versionName"],
+			manifestData["versionCode"],
+			unityOptions.ProjectRoot,
+			globalOptions,
+			unityOptions.Overwrite,
+			logger,
+		)
 
-			if err != nil {
-				return err
-			}
+		if err != nil {
+			return err
+		}
 
+		for _, symbolPath := range symbolFileList {
 			if filepath.Base(symbolPath) == "libil2cpp.so" && !unityOptions.UnityShared.NoUploadIl2cppMapping {
 				buildId, _ := elf.GetBuildId(symbolPath)
 				logger.Info(fmt.Sprintf("Uploading %s for build ID %s", lineMappingFile, buildId))
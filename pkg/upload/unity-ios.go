@@ -150,13 +150,15 @@ func ProcessUnityIos(globalOptions options.CLI, logger log.Logger) error {
 					return fmt.Errorf("dSYM %s has no UUID, cannot upload line mappings", dsym.Name)
 				}
 			}
+		}
 
-			err := ios.ProcessDsymUpload(plistPath, unityOptions.DsymShared.ProjectRoot, globalOptions, []*ios.DwarfInfo{dsym}, logger)
+		err = ios.ProcessDsymUpload(plistPath, unityOptions.DsymShared.ProjectRoot, globalOptions, dsyms, logger)
 
-			if err != nil {
-				return fmt.Errorf("Error uploading dSYM files: %w", err)
-			}
+		if err != nil {
+			return fmt.Errorf("Error uploading dSYM files: %w", err)
+		}
 
+		for _, dsym := range dsyms {
 			if dsym.Name == "UnityFramework" && lineMappingFile != "" {
 				logger.Info(fmt.Sprintf("Uploading %s for dSYM %s, withID %s", lineMappingFile, dsym.Name, dsym.UUID))
 
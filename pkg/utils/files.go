@@ -16,7 +16,6 @@ import (
 const (
 	PLUTIL     = "plutil"
 	XCODEBUILD = "xcodebuild"
-	DWARFDUMP  = "dwarfdump"
 )
 
 // FilePathWalkDir recursively finds all files within a given directory.
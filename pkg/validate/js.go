@@ -0,0 +1,361 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+)
+
+// SourceMapResult is the outcome of validating a single source map.
+type SourceMapResult struct {
+	SourceMapPath string
+	BundlePath    string
+	Errors        []string
+	Warnings      []string
+}
+
+// Valid reports whether no errors were found in the source map.
+func (r *SourceMapResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *SourceMapResult) addError(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *SourceMapResult) addWarning(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// offset is a zero-based position in the bundle, such as the start of a section of an index map.
+type offset struct {
+	line   int
+	column int
+}
+
+// ValidateSourceMap checks a source map for problems that would prevent stack traces from
+// being mapped: invalid mappings, a `file` that does not match the bundle, sources that
+// cannot be found and have no sourcesContent, and mappings outside of the bundle or sources.
+//
+// Parameters:
+// - sourceMapPath: path to the source map file.
+// - bundlePath: path to the bundle the source map relates to, or empty if it is not known.
+// - sampleSize: the number of mappings to check against the bundle and sources, or 0 for all of them.
+// - logger: logger instance.
+//
+// Returns:
+// - SourceMapResult: the errors and warnings found in the source map.
+func ValidateSourceMap(sourceMapPath string, bundlePath string, sampleSize int, logger log.Logger) SourceMapResult {
+	result := SourceMapResult{SourceMapPath: sourceMapPath, BundlePath: bundlePath}
+
+	contents, err := upload.ReadSourceMap(sourceMapPath, logger)
+	if err != nil {
+		result.addError("%s", err)
+		return result
+	}
+
+	if version, _ := contents["version"].(float64); version != 3 {
+		result.addError("unsupported source map version %v, expected 3", contents["version"])
+	}
+
+	var bundleLines []int
+	if bundlePath == "" {
+		result.addWarning("no bundle was found for the source map, so the mappings cannot be checked against it")
+	} else {
+		bundleLines = checkBundle(contents, sourceMapPath, bundlePath, logger, &result)
+	}
+
+	if untypedSections, isIndexMap := contents["sections"]; isIndexMap {
+		sections, ok := untypedSections.([]interface{})
+		if !ok {
+			result.addError("the sections field is not a list")
+			return result
+		}
+		for i, untypedSection := range sections {
+			section, _ := untypedSection.(map[string]interface{})
+			sectionMap, _ := section["map"].(map[string]interface{})
+			sectionOffset, _ := section["offset"].(map[string]interface{})
+			if sectionMap == nil || sectionOffset == nil {
+				result.addError("section %d must have an offset and map", i)
+				continue
+			}
+			line, _ := sectionOffset["line"].(float64)
+			column, _ := sectionOffset["column"].(float64)
+			validateSection(sectionMap, fmt.Sprintf("section %d: ", i), offset{int(line), int(column)}, sourceMapPath, bundleLines, sampleSize, &result)
+		}
+	} else {
+		validateSection(contents, "", offset{}, sourceMapPath, bundleLines, sampleSize, &result)
+	}
+
+	return result
+}
+
+// checkBundle checks that the source map's file field and the bundle's sourceMappingURL
+// comment refer to each other, and returns the UTF-16 length of each line of the bundle.
+func checkBundle(contents map[string]interface{}, sourceMapPath string, bundlePath string, logger log.Logger, result *SourceMapResult) []int {
+	if file, ok := contents["file"].(string); !ok || file == "" {
+		result.addWarning("the source map has no file field")
+	} else if filepath.Base(file) != filepath.Base(bundlePath) {
+		result.addError("the file field %q does not match the bundle %s", file, bundlePath)
+	}
+
+	sourceMappingURL, err := upload.ExtractSourceMappingURL(bundlePath, logger)
+	if err != nil {
+		result.addError("%s", err)
+		return nil
+	}
+	if sourceMappingURL == "" {
+		result.addWarning("the bundle %s has no sourceMappingURL comment", bundlePath)
+	} else if !strings.HasPrefix(sourceMappingURL, "data:") && !strings.Contains(sourceMappingURL, "://") {
+		referenced := sourceMappingURL
+		if !filepath.IsAbs(referenced) {
+			referenced = filepath.Join(filepath.Dir(bundlePath), referenced)
+		}
+		if !samePath(referenced, sourceMapPath) {
+			result.addError("the bundle %s refers to a different source map: %s", bundlePath, sourceMappingURL)
+		}
+	}
+
+	data, err := os.ReadFile(bundlePath)
+	if err != nil {
+		result.addError("cannot read bundle file %s: %s", bundlePath, err)
+		return nil
+	}
+	return lineLengths(string(data))
+}
+
+// validateSection checks the sources and mappings of a source map, or of one section of an index map.
+func validateSection(section map[string]interface{}, prefix string, start offset, sourceMapPath string, bundleLines []int, sampleSize int, result *SourceMapResult) {
+	if _, hasUrl := section["url"]; hasUrl {
+		result.addError("%ssections that refer to another source map by url are not supported", prefix)
+		return
+	}
+
+	sources, ok := section["sources"].([]interface{})
+	if !ok {
+		result.addError("%sthe source map has no sources list", prefix)
+		return
+	}
+	names, _ := section["names"].([]interface{})
+	sourcesContent, _ := section["sourcesContent"].([]interface{})
+	if sourcesContent != nil && len(sourcesContent) != len(sources) {
+		result.addWarning("%ssourcesContent has %d entries but there are %d sources", prefix, len(sourcesContent), len(sources))
+	}
+
+	// The UTF-16 length of each line of each source, or nil if the source cannot be read
+	sourceLines := make([][]int, len(sources))
+	for i, untypedSource := range sources {
+		if i < len(sourcesContent) {
+			if content, ok := sourcesContent[i].(string); ok {
+				sourceLines[i] = lineLengths(content)
+				continue
+			}
+		}
+
+		source, ok := untypedSource.(string)
+		if !ok {
+			continue
+		}
+		sourcePath, isFile := upload.ResolveSourcePath(source, sourceMapPath)
+		if !isFile {
+			result.addWarning("%ssource %q is a virtual webpack module and has no sourcesContent", prefix, source)
+			continue
+		}
+		content, err := os.ReadFile(sourcePath)
+		if err != nil {
+			result.addError("%ssource %q cannot be found at %s and has no sourcesContent", prefix, source, sourcePath)
+			continue
+		}
+		sourceLines[i] = lineLengths(string(content))
+	}
+
+	mappings, ok := section["mappings"].(string)
+	if !ok {
+		result.addError("%sthe source map has no mappings", prefix)
+		return
+	}
+	segments, err := sourcemap.DecodeMappings(mappings)
+	if err != nil {
+		result.addError("%sinvalid mappings: %s", prefix, err)
+		return
+	}
+	if len(segments) == 0 {
+		result.addWarning("%sthe source map has no mappings", prefix)
+		return
+	}
+
+	var badSource, badName, outsideBundle, outsideSource problemCount
+	for i, segment := range segments {
+		generatedLine := start.line + segment.GeneratedLine
+		generatedColumn := segment.GeneratedColumn
+		if segment.GeneratedLine == 0 {
+			generatedColumn += start.column
+		}
+		position := offset{generatedLine, generatedColumn}
+
+		if segment.HasSource && segment.SourceIndex >= len(sources) {
+			badSource.add(position)
+		}
+		if segment.HasName && segment.NameIndex >= len(names) {
+			badName.add(position)
+		}
+
+		if !isSampled(i, len(segments), sampleSize) {
+			continue
+		}
+		if bundleLines != nil && !inRange(bundleLines, generatedLine, generatedColumn) {
+			outsideBundle.add(position)
+		}
+		if segment.HasSource && segment.SourceIndex < len(sources) && sourceLines[segment.SourceIndex] != nil &&
+			!inRange(sourceLines[segment.SourceIndex], segment.OriginalLine, segment.OriginalColumn) {
+			outsideSource.add(position)
+		}
+	}
+
+	sampled := min(len(segments), sampleSize)
+	if sampleSize <= 0 {
+		sampled = len(segments)
+	}
+	badSource.report(result, prefix, "mappings refer to a source that is not in the sources list")
+	badName.report(result, prefix, "mappings refer to a name that is not in the names list")
+	outsideBundle.report(result, prefix, fmt.Sprintf("of %d sampled mappings are outside of the bundle", sampled))
+	outsideSource.report(result, prefix, fmt.Sprintf("of %d sampled mappings are outside of their original source", sampled))
+}
+
+// problemCount counts a problem found in several mappings, recording where it was first seen.
+type problemCount struct {
+	count int
+	first offset
+}
+
+func (p *problemCount) add(position offset) {
+	if p.count == 0 {
+		p.first = position
+	}
+	p.count++
+}
+
+func (p *problemCount) report(result *SourceMapResult, prefix string, description string) {
+	if p.count > 0 {
+		result.addError("%s%d %s, first at generated line %d, column %d", prefix, p.count, description, p.first.line+1, p.first.column)
+	}
+}
+
+// isSampled reports whether the mapping at index i is one of sampleSize evenly spaced
+// mappings out of total.
+func isSampled(i int, total int, sampleSize int) bool {
+	if sampleSize <= 0 || total <= sampleSize {
+		return true
+	}
+	step := total / sampleSize
+	return i%step == 0 && i/step < sampleSize
+}
+
+// lineLengths returns the length of each line of a file in UTF-16 code units, which is how
+// columns are counted in source maps. The lengths are computed once per file, rather than
+// for every mapping checked against them.
+func lineLengths(content string) []int {
+	lines := upload.SplitLines(content)
+	lengths := make([]int, len(lines))
+	for i, line := range lines {
+		for _, r := range line {
+			lengths[i] += utf16.RuneLen(r)
+		}
+	}
+	return lengths
+}
+
+// inRange reports whether a zero-based line and UTF-16 column are within lines of the given lengths.
+func inRange(lengths []int, line int, column int) bool {
+	if line >= len(lengths) {
+		return false
+	}
+	return column < lengths[line]
+}
+
+// samePath reports whether two paths refer to the same file.
+func samePath(a string, b string) bool {
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	if aErr != nil || bErr != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(aInfo, bInfo)
+}
+
+// ProcessValidateJs validates the JavaScript source maps found at each path, reporting the
+// problems found in each one.
+//
+// Parameters:
+// - globalOptions: CLI options including the paths to validate.
+// - logger: logger instance for outputting progress and errors.
+//
+// Returns:
+// - error: if a source map cannot be found or has problems.
+func ProcessValidateJs(globalOptions options.CLI, logger log.Logger) error {
+	jsOptions := globalOptions.Validate.Js
+
+	total, invalid := 0, 0
+	for _, path := range jsOptions.Path {
+		// If the path is a .map file, treat the path as the source map itself
+		sourceMapPath, outputPath := upload.ResolveSourceMapArgument(path, jsOptions.SourceMap)
+
+		sourceMapBundles, err := upload.ResolveSourceMapPaths(sourceMapPath, jsOptions.Bundle, outputPath, logger)
+		if err != nil {
+			return err
+		}
+		if len(sourceMapBundles) == 0 {
+			return fmt.Errorf("could not find a source map in %s, please specify the path by using --source-map", path)
+		}
+
+		for _, bundle := range sourceMapBundles {
+			result := ValidateSourceMap(bundle.SourceMapPath, bundle.BundlePath, jsOptions.SampleSize, logger)
+			reportResult(result, logger)
+
+			total++
+			if !result.Valid() {
+				invalid++
+			}
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d source maps have problems", invalid, total)
+	}
+
+	logger.Info(fmt.Sprintf("%d source maps are valid", total))
+	return nil
+}
+
+// reportResult logs the problems found in a source map and records its result.
+func reportResult(result SourceMapResult, logger log.Logger) {
+	for _, warning := range result.Warnings {
+		logger.Warn(fmt.Sprintf("%s: %s", result.SourceMapPath, warning))
+	}
+	for _, problem := range result.Errors {
+		logger.Error(fmt.Sprintf("%s: %s", result.SourceMapPath, problem))
+	}
+
+	fileResult := log.FileResult{
+		File:     result.SourceMapPath,
+		Status:   log.FileValid,
+		Warnings: result.Warnings,
+	}
+	if result.BundlePath != "" {
+		fileResult.Identifiers = map[string]string{"bundle": result.BundlePath}
+	}
+	if !result.Valid() {
+		fileResult.Status = log.FileInvalid
+		fileResult.Error = strings.Join(result.Errors, "; ")
+	} else {
+		logger.Info(fmt.Sprintf("Source map %s is valid", result.SourceMapPath))
+	}
+	log.ReportFile(logger, fileResult)
+}
@@ -0,0 +1,82 @@
+package breakpad_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/breakpad"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSymFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "module.sym")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+func TestReadModule(t *testing.T) {
+	t.Log("Testing reading the MODULE and INFO CODE_ID records from a symbol file")
+
+	module, err := breakpad.ReadModule("../../features/breakpad/fixtures/breakpad-symbols.sym")
+
+	require.NoError(t, err)
+	assert.Equal(t, &breakpad.Module{
+		Os:     "Linux",
+		Arch:   "x86_64",
+		Id:     "CB77944DB22F6H3S00000000000000000",
+		Name:   "chrome",
+		CodeId: "4D9421CB5BB12E90",
+	}, module)
+}
+
+func TestReadModuleWithCodeFile(t *testing.T) {
+	t.Log("Testing reading a module whose name contains spaces and has a code file")
+
+	path := writeSymFile(t, "MODULE windows x86 5A9832E5287241C1838ED98914E9B7FF1 my app.pdb\r\n"+
+		"INFO CODE_ID 5B5F6E5A1000 my app.exe\r\n"+
+		"FILE 0 main.cpp\r\n")
+	module, err := breakpad.ReadModule(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, &breakpad.Module{
+		Os:       "windows",
+		Arch:     "x86",
+		Id:       "5A9832E5287241C1838ED98914E9B7FF1",
+		Name:     "my app.pdb",
+		CodeId:   "5B5F6E5A1000",
+		CodeFile: "my app.exe",
+	}, module)
+}
+
+func TestReadModuleRoundTrip(t *testing.T) {
+	t.Log("Testing that the module of a created symbol file can be read back")
+
+	symbols, err := breakpad.CreateSymbolFile(dartSymbols, "Linux")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "app.sym")
+	out, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = symbols.WriteTo(out)
+	require.NoError(t, err)
+	require.NoError(t, out.Close())
+
+	module, err := breakpad.ReadModule(path)
+	require.NoError(t, err)
+	assert.Equal(t, symbols.Module, *module)
+}
+
+func TestReadModuleInvalid(t *testing.T) {
+	t.Log("Testing that files without a MODULE record return an error")
+
+	_, err := breakpad.ReadModule(writeSymFile(t, "FILE 0 main.cpp\n"))
+	assert.Error(t, err)
+
+	_, err = breakpad.ReadModule(writeSymFile(t, ""))
+	assert.Error(t, err)
+
+	_, err = breakpad.ReadModule(writeSymFile(t, "MODULE Linux x86_64\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package breakpad_testing
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/breakpad"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dartSymbols = "../../features/dart/fixtures/app-debug-info/app.android-arm64.symbols"
+
+func TestCreateSymbolFile(t *testing.T) {
+	t.Log("Testing creating a Breakpad symbol file from an ELF binary with DWARF information")
+
+	symbols, err := breakpad.CreateSymbolFile(dartSymbols, "Linux")
+	require.NoError(t, err)
+
+	assert.Equal(t, breakpad.Module{
+		Os:     "Linux",
+		Arch:   "arm64",
+		Id:     "1C13CC0703A824C1E93268CE193227370",
+		Name:   "app.android-arm64.symbols",
+		CodeId: "07CC131CA803C124E93268CE19322737",
+	}, symbols.Module)
+	assert.True(t, symbols.HasDwarf)
+	assert.NotEmpty(t, symbols.Files)
+	assert.NotEmpty(t, symbols.Funcs)
+
+	for i, function := range symbols.Funcs {
+		if i > 0 {
+			previous := symbols.Funcs[i-1]
+			assert.LessOrEqual(t, previous.Address+previous.Size, function.Address, "functions should be sorted and not overlap")
+		}
+		for _, line := range function.Lines {
+			assert.GreaterOrEqual(t, line.Address, function.Address)
+			assert.LessOrEqual(t, line.Address+line.Size, function.Address+function.Size)
+			assert.Less(t, line.File, len(symbols.Files))
+		}
+	}
+}
+
+func TestWriteSymbolFile(t *testing.T) {
+	t.Log("Testing writing a symbol file in the Breakpad text format")
+
+	symbols := &breakpad.SymbolFile{
+		Module:  breakpad.Module{Os: "Linux", Arch: "x86_64", Id: "BD6924FFB6D5742655FB9FD070B6FCEC0", Name: "a", CodeId: "FF2469BD"},
+		Files:   []string{"/src/a.cpp"},
+		Funcs:   []breakpad.Func{{Address: 0x113a, Size: 0x12, Name: "foo::Bar::baz", Lines: []breakpad.Line{{Address: 0x113a, Size: 0x12, Line: 2, File: 0}}}},
+		Publics: []breakpad.Public{{Address: 0x1000, Name: "_init"}},
+	}
+
+	var out bytes.Buffer
+	n, err := symbols.WriteTo(&out)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	assert.Equal(t, strings.Join([]string{
+		"MODULE Linux x86_64 BD6924FFB6D5742655FB9FD070B6FCEC0 a",
+		"INFO CODE_ID FF2469BD",
+		"FILE 0 /src/a.cpp",
+		"FUNC 113a 12 0 foo::Bar::baz",
+		"113a 12 2 0",
+		"PUBLIC 1000 0 _init",
+		"",
+	}, "\n"), out.String())
+}
+
+func TestProcessCreateBreakpadSymbols(t *testing.T) {
+	t.Log("Testing creating symbol files for multiple binaries")
+
+	names := []string{"app.android-arm.symbols", "app.android-arm64.symbols", "app.android-x64.symbols"}
+	outputDir := filepath.Join(t.TempDir(), "symbols")
+	opts := options.CLI{}
+	for _, name := range names {
+		opts.CreateBreakpadSymbols.Path = append(opts.CreateBreakpadSymbols.Path, filepath.Join(filepath.Dir(dartSymbols), name))
+	}
+	opts.CreateBreakpadSymbols.OutputDir = outputDir
+	opts.CreateBreakpadSymbols.OsName = "Linux"
+
+	err := breakpad.ProcessCreateBreakpadSymbols(opts, log.NewLoggerWrapper("debug", log.OutputText))
+	require.NoError(t, err)
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(outputDir, name+".sym"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "MODULE Linux "), name)
+	}
+}
+
+func TestProcessCreateBreakpadSymbolsNoBinaries(t *testing.T) {
+	t.Log("Testing that an error is returned when no ELF binaries are found")
+
+	opts := options.CLI{}
+	opts.CreateBreakpadSymbols.Path = []string{"../testdata/android"}
+	opts.CreateBreakpadSymbols.OutputDir = t.TempDir()
+
+	err := breakpad.ProcessCreateBreakpadSymbols(opts, log.NewLoggerWrapper("debug", log.OutputText))
+	assert.Error(t, err)
+}
+
+func TestCreateSymbolFileUnidentifiable(t *testing.T) {
+	t.Log("Testing that a binary without a build ID or .text section cannot be used")
+
+	_, err := breakpad.CreateSymbolFile("../../features/dart/fixtures/app-debug-info/app.ios-arm64.symbols", "Linux")
+	assert.ErrorContains(t, err, "no build ID or .text section")
+}
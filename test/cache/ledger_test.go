@@ -0,0 +1,84 @@
+package cache_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerRecordAndGet(t *testing.T) {
+	t.Log("Testing recording an upload and looking it up")
+
+	ledger, err := cache.Open(filepath.Join(t.TempDir(), "uploads"))
+	require.NoError(t, err)
+
+	key := cache.Key("api-key", "https://upload.bugsnag.com/ndk-symbol", nil, map[string]string{"soFile": "abc"})
+	_, ok := ledger.Get(key)
+	assert.False(t, ok)
+
+	entry := cache.Entry{File: "libexample.so", Endpoint: "https://upload.bugsnag.com/ndk-symbol", UploadedAt: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, ledger.Record(key, entry))
+
+	recorded, ok := ledger.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, entry, recorded)
+}
+
+func TestKey(t *testing.T) {
+	t.Log("Testing that upload keys depend on every identifying value")
+
+	fields := map[string]string{"appId": "com.example", "versionCode": "1"}
+	files := map[string]string{"soFile": "libexample.so:abc"}
+	key := cache.Key("api-key", "https://upload.bugsnag.com/ndk-symbol", fields, files)
+
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, cache.Key("api-key", "https://upload.bugsnag.com/ndk-symbol",
+		map[string]string{"versionCode": "1", "appId": "com.example"}, files))
+	assert.NotEqual(t, key, cache.Key("other-api-key", "https://upload.bugsnag.com/ndk-symbol", fields, files))
+	assert.NotEqual(t, key, cache.Key("api-key", "https://upload.bugsnag.com/proguard", fields, files))
+	assert.NotEqual(t, key, cache.Key("api-key", "https://upload.bugsnag.com/ndk-symbol",
+		map[string]string{"appId": "com.example", "versionCode": "2"}, files))
+	assert.NotEqual(t, key, cache.Key("api-key", "https://upload.bugsnag.com/ndk-symbol",
+		fields, map[string]string{"soFile": "libexample.so:def"}))
+}
+
+func TestLedgerPrune(t *testing.T) {
+	t.Log("Testing pruning old entries from the ledger")
+
+	dir := t.TempDir()
+	ledger, err := cache.Open(dir)
+	require.NoError(t, err)
+
+	oldKey := cache.Key("api-key", "old", nil, nil)
+	newKey := cache.Key("api-key", "new", nil, nil)
+	require.NoError(t, ledger.Record(oldKey, cache.Entry{File: "old"}))
+	require.NoError(t, ledger.Record(newKey, cache.Entry{File: "new"}))
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, oldKey+".json"), old, old))
+
+	// Files that were not created by the ledger are left alone
+	unrelated := filepath.Join(dir, "settings.json")
+	require.NoError(t, os.WriteFile(unrelated, []byte("{}"), 0644))
+	require.NoError(t, os.Chtimes(unrelated, old, old))
+
+	removed, err := ledger.Prune(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, ok := ledger.Get(oldKey)
+	assert.False(t, ok)
+	_, ok = ledger.Get(newKey)
+	assert.True(t, ok)
+
+	removed, err = ledger.Prune(0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, ok = ledger.Get(newKey)
+	assert.False(t, ok)
+	assert.FileExists(t, unrelated)
+}
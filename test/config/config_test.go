@@ -0,0 +1,128 @@
+package config_testing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/bugsnag/bugsnag-cli/pkg/config"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+api-key: top-level-key
+version-name: 1.0.0
+upload:
+  retries: 2
+  retry_backoff: 5s
+  exclude:
+    - "*.map"
+    - "node_modules/**"
+  android-ndk:
+    variant: release
+    version-name: 2.0.0
+  all:
+    upload-options:
+      fileNameField: proguard
+`
+
+// parseWithConfig writes the configuration to the parent of a temporary working directory
+// and parses the arguments with the resolver loaded from the working directory.
+func parseWithConfig(t *testing.T, contents string, args ...string) (options.CLI, *kong.Context, *config.Resolver) {
+	root := t.TempDir()
+	workingDir := filepath.Join(root, "app", "src")
+	assert.NoError(t, os.MkdirAll(workingDir, 0755))
+	if contents != "" {
+		assert.NoError(t, os.WriteFile(filepath.Join(root, "app", config.FileName), []byte(contents), 0644))
+	}
+
+	resolver, err := config.Load(workingDir)
+	assert.NoError(t, err)
+
+	cli := options.CLI{}
+	parser, err := kong.New(&cli, kong.Resolvers(resolver))
+	assert.NoError(t, err)
+
+	ctx, err := parser.Parse(args)
+	assert.NoError(t, err)
+
+	return cli, ctx, resolver
+}
+
+func TestConfigFileIsDiscovered(t *testing.T) {
+	t.Log("Testing that the configuration file is found in a parent directory")
+
+	_, _, resolver := parseWithConfig(t, testConfig, "upload", "android-ndk", ".")
+
+	assert.True(t, strings.HasSuffix(resolver.Path, filepath.Join("app", config.FileName)))
+	assert.FileExists(t, resolver.Path)
+}
+
+func TestConfigFileSections(t *testing.T) {
+	t.Log("Testing that command sections take precedence over parent sections and the top level")
+
+	cli, _, _ := parseWithConfig(t, testConfig, "upload", "android-ndk", ".")
+
+	assert.Equal(t, "top-level-key", cli.ApiKey)
+	assert.Equal(t, 2, cli.Upload.Retries)
+	assert.Equal(t, 5*time.Second, cli.Upload.RetryBackoff)
+	assert.Equal(t, []string{"*.map", "node_modules/**"}, cli.Upload.Exclude)
+	assert.Equal(t, "release", cli.Upload.AndroidNdk.Variant)
+	assert.Equal(t, "2.0.0", cli.Upload.AndroidNdk.VersionName)
+
+	cli, _, _ = parseWithConfig(t, testConfig, "upload", "all", ".")
+	assert.Equal(t, map[string]string{"fileNameField": "proguard"}, cli.Upload.All.UploadOptions)
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	t.Log("Testing that flags take precedence over environment variables, which take precedence over the configuration file")
+
+	t.Setenv("BUGSNAG_API_KEY", "env-key")
+	t.Setenv("BUGSNAG_RETRIES", "4")
+
+	cli, ctx, resolver := parseWithConfig(t, testConfig, "upload", "android-ndk", "--retries=7", "--timeout=10", ".")
+
+	assert.Equal(t, "env-key", cli.ApiKey)
+	assert.Equal(t, 7, cli.Upload.Retries)
+	assert.Equal(t, "release", cli.Upload.AndroidNdk.Variant)
+
+	sources := map[string]string{}
+	for _, path := range ctx.Path {
+		for _, flag := range path.Flags {
+			sources[flag.Name] = resolver.Source(ctx, flag)
+		}
+	}
+
+	assert.Equal(t, "env BUGSNAG_API_KEY", sources["api-key"])
+	assert.Equal(t, config.SourceFlag, sources["retries"])
+	assert.Equal(t, config.SourceFlag, sources["timeout"])
+	assert.Equal(t, "config "+resolver.Path, sources["variant"])
+	assert.Equal(t, config.SourceDefault, sources["log-level"])
+	assert.Equal(t, "", sources["application-id"])
+}
+
+func TestNoConfigFile(t *testing.T) {
+	t.Log("Testing that environment variables are applied without a configuration file")
+
+	t.Setenv("BUGSNAG_API_KEY", "env-key")
+
+	cli, _, resolver := parseWithConfig(t, "", "upload", "android-ndk", ".")
+
+	assert.Equal(t, "", resolver.Path)
+	assert.Equal(t, "env-key", cli.ApiKey)
+	assert.Equal(t, 0, cli.Upload.Retries)
+}
+
+func TestInvalidConfigFile(t *testing.T) {
+	t.Log("Testing that an invalid configuration file is reported")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, config.FileName), []byte("api-key: [unterminated"), 0644))
+
+	_, err := config.Load(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package debugid_testing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/debugid"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtures = "../../features/js/fixtures/js-webpack5/dist"
+
+// copyFixture copies the webpack bundle and source map into a temporary directory.
+func copyFixture(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.js", "main.js.map"} {
+		data, err := os.ReadFile(filepath.Join(fixtures, name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+	return filepath.Join(dir, "main.js"), filepath.Join(dir, "main.js.map")
+}
+
+func TestInjectDebugId(t *testing.T) {
+	t.Log("Testing injecting the same debug ID into a bundle and its source map")
+
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
+	bundlePath, sourceMapPath := copyFixture(t)
+	original, err := os.ReadFile(bundlePath)
+	require.NoError(t, err)
+
+	debugId, err := debugid.InjectDebugId(bundlePath, sourceMapPath, false, logger)
+	require.NoError(t, err)
+	assert.Equal(t, sourcemap.GenerateDebugId(original), debugId)
+
+	bundle, err := os.ReadFile(bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, debugId, sourcemap.BundleDebugId(bundle))
+
+	sourceMapContents, err := upload.ReadSourceMap(sourceMapPath, logger)
+	require.NoError(t, err)
+	assert.Equal(t, debugId, sourcemap.SourceMapDebugId(sourceMapContents))
+	assert.Equal(t, debugId, upload.ResolveDebugId(sourceMapContents, bundlePath, logger))
+
+	// The sourceMappingURL comment must still be found
+	sourceMapURL, err := upload.ExtractSourceMappingURL(bundlePath, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "main.js.map", sourceMapURL)
+
+	// Injecting again leaves both files unchanged
+	sourceMap, err := os.ReadFile(sourceMapPath)
+	require.NoError(t, err)
+	again, err := debugid.InjectDebugId(bundlePath, sourceMapPath, false, logger)
+	require.NoError(t, err)
+	assert.Equal(t, debugId, again)
+	assertFileContents(t, bundlePath, bundle)
+	assertFileContents(t, sourceMapPath, sourceMap)
+}
+
+func TestInjectDebugIdKeepsSourceMapId(t *testing.T) {
+	t.Log("Testing that a debug ID already in the source map is injected into its bundle")
+
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
+	bundlePath, sourceMapPath := copyFixture(t)
+
+	var sourceMapContents map[string]interface{}
+	sourceMap, err := os.ReadFile(sourceMapPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(sourceMap, &sourceMapContents))
+	sourceMapContents["debugId"] = "6d3b8e0e-1f4a-4c57-9d0a-3b1e2f4c5d6e"
+	sourceMap, err = json.Marshal(sourceMapContents)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(sourceMapPath, sourceMap, 0644))
+
+	debugId, err := debugid.InjectDebugId(bundlePath, sourceMapPath, false, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "6d3b8e0e-1f4a-4c57-9d0a-3b1e2f4c5d6e", debugId)
+	assertFileContents(t, sourceMapPath, sourceMap)
+
+	bundle, err := os.ReadFile(bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, debugId, sourcemap.BundleDebugId(bundle))
+}
+
+func TestInjectDebugIdDryRun(t *testing.T) {
+	t.Log("Testing that a dry run does not change the bundle or source map")
+
+	bundlePath, sourceMapPath := copyFixture(t)
+	_, err := debugid.InjectDebugId(bundlePath, sourceMapPath, true, log.NewLoggerWrapper("debug", log.OutputText))
+	require.NoError(t, err)
+
+	for _, name := range []string{"main.js", "main.js.map"} {
+		original, err := os.ReadFile(filepath.Join(fixtures, name))
+		require.NoError(t, err)
+		assertFileContents(t, filepath.Join(filepath.Dir(bundlePath), name), original)
+	}
+}
+
+func assertFileContents(t *testing.T, path string, expected []byte) {
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(contents))
+}
@@ -0,0 +1,90 @@
+package elf_testing
+
+import (
+	"debug/elf"
+	"path/filepath"
+	"testing"
+
+	bugsnagElf "github.com/bugsnag/bugsnag-cli/pkg/elf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nativeLibs = "../../features/android/fixtures/app/build/intermediates/merged_native_libs/release/out/lib"
+
+func TestExtractDebugSections(t *testing.T) {
+	t.Log("Testing extracting and compressing debug sections from ELF files")
+
+	for _, abi := range []string{"arm64-v8a", "armeabi-v7a", "x86", "x86_64"} {
+		input := filepath.Join(nativeLibs, abi, "libbugsnag-ndk.so")
+		output := filepath.Join(t.TempDir(), "libbugsnag-ndk.so.sym")
+
+		require.NoError(t, bugsnagElf.ExtractDebugSections(input, output), abi)
+
+		original, err := elf.Open(input)
+		require.NoError(t, err)
+		defer original.Close()
+
+		extracted, err := elf.Open(output)
+		require.NoError(t, err)
+		defer extracted.Close()
+
+		// The build ID is used to match the symbols to crashes
+		expectedBuildId, err := bugsnagElf.GetBuildId(input)
+		require.NoError(t, err)
+		buildId, err := bugsnagElf.GetBuildId(output)
+		require.NoError(t, err)
+		assert.Equal(t, expectedBuildId, buildId, abi)
+
+		// Section indexes and addresses are unchanged, but loadable sections have no contents
+		assert.Equal(t, original.Machine, extracted.Machine, abi)
+		require.Equal(t, len(original.Sections), len(extracted.Sections), abi)
+		for i, section := range extracted.Sections {
+			assert.Equal(t, original.Sections[i].Name, section.Name, abi)
+			assert.Equal(t, original.Sections[i].Addr, section.Addr, abi)
+		}
+		assert.Equal(t, elf.SHT_NOBITS, extracted.Section(".text").Type, abi)
+	}
+}
+
+func TestExtractDebugSectionsCompressesDwarf(t *testing.T) {
+	t.Log("Testing that DWARF sections are compressed without changing their contents")
+
+	for _, arch := range []string{"arm", "arm64", "x64"} {
+		input := filepath.Join("../../features/dart/fixtures/app-debug-info", "app.android-"+arch+".symbols")
+		output := filepath.Join(t.TempDir(), "app.symbols")
+
+		require.NoError(t, bugsnagElf.ExtractDebugSections(input, output), arch)
+
+		original, err := elf.Open(input)
+		require.NoError(t, err)
+		defer original.Close()
+
+		extracted, err := elf.Open(output)
+		require.NoError(t, err)
+		defer extracted.Close()
+
+		for _, name := range []string{".debug_abbrev", ".debug_info", ".debug_line"} {
+			section := extracted.Section(name)
+			require.NotNil(t, section, arch+" "+name)
+			assert.NotZero(t, section.Flags&elf.SHF_COMPRESSED, arch+" "+name)
+
+			expected, err := original.Section(name).Data()
+			require.NoError(t, err)
+			actual, err := section.Data()
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, arch+" "+name)
+		}
+
+		_, err = extracted.DWARF()
+		assert.NoError(t, err, arch)
+	}
+}
+
+func TestExtractDebugSectionsNotElf(t *testing.T) {
+	t.Log("Testing that extracting debug sections from a file that is not ELF returns an error")
+
+	err := bugsnagElf.ExtractDebugSections("../testdata/android/AndroidManifest.xml", filepath.Join(t.TempDir(), "out"))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package elf_testing
+
+import (
+	"testing"
+
+	bugsnagElf "github.com/bugsnag/bugsnag-cli/pkg/elf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const crashLib = "../testdata/native/libcrash.so"
+
+func TestSymbolizeInlinedFunctions(t *testing.T) {
+	t.Log("Testing symbolizing an address within functions inlined into each other")
+
+	symbolizer, err := bugsnagElf.NewSymbolizer(crashLib)
+	require.NoError(t, err)
+	assert.True(t, symbolizer.HasDwarf)
+
+	assert.Equal(t, []bugsnagElf.SymbolizedFrame{
+		{Function: "trigger", File: "crash.c", Line: 7, Column: 8},
+		{Function: "handle", File: "crash.c", Line: 11, Column: 5},
+		{Function: "crash", File: "crash.c", Line: 15, Column: 5},
+	}, symbolizer.Symbolize(0x1110))
+
+	assert.Equal(t, []bugsnagElf.SymbolizedFrame{
+		{Function: "crash", File: "crash.c", Line: 16, Column: 13},
+	}, symbolizer.Symbolize(0x1116))
+
+	assert.Nil(t, symbolizer.Symbolize(0x10))
+}
+
+func TestSymbolizeWithoutDwarf(t *testing.T) {
+	t.Log("Testing symbolizing an address using only the symbol table")
+
+	symbolizer, err := bugsnagElf.NewSymbolizer("../testdata/native/stripped/libcrash.so")
+	require.NoError(t, err)
+	assert.False(t, symbolizer.HasDwarf)
+
+	assert.Equal(t, []bugsnagElf.SymbolizedFrame{{Function: "entry"}}, symbolizer.Symbolize(0x1125))
+}
@@ -0,0 +1,100 @@
+package ios_test
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	x86Dwarf   = "../testdata/ios/dsym-test-fixtures/dsyms/app.dSYM/Contents/Resources/DWARF/app"
+	arm64Dwarf = "../../features/xcode/fixtures/swift-package-manager/swift-package-manager.app.dSYM/Contents/Resources/DWARF/swift-package-manager"
+)
+
+// writeFatBinary combines thin Mach-O files into a universal binary.
+func writeFatBinary(t *testing.T, cpuTypes []uint32, paths ...string) string {
+	const align = 12 // 4096-byte alignment
+	header := make([]byte, 8+20*len(paths))
+	binary.BigEndian.PutUint32(header[0:], 0xcafebabe)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(paths)))
+
+	data := make([]byte, len(header))
+	for i, path := range paths {
+		contents, err := os.ReadFile(path)
+		assert.NoError(t, err)
+
+		offset := (len(data) + (1 << align) - 1) &^ ((1 << align) - 1)
+		data = append(data, make([]byte, offset-len(data))...)
+
+		entry := header[8+20*i:]
+		binary.BigEndian.PutUint32(entry[0:], cpuTypes[i])
+		binary.BigEndian.PutUint32(entry[4:], 0)
+		binary.BigEndian.PutUint32(entry[8:], uint32(offset))
+		binary.BigEndian.PutUint32(entry[12:], uint32(len(contents)))
+		binary.BigEndian.PutUint32(entry[16:], align)
+		data = append(data, contents...)
+	}
+	copy(data, header)
+
+	out := filepath.Join(t.TempDir(), "universal")
+	assert.NoError(t, os.WriteFile(out, data, 0644))
+	return out
+}
+
+func TestGetMachoSlices(t *testing.T) {
+	t.Log("Testing reading the UUID, arch and DWARF presence from a Mach-O file")
+
+	slices, err := ios.GetMachoSlices(x86Dwarf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ios.MachoSlice{
+		{UUID: "3ADB330A-1C19-3B98-A531-D9E09FAA3A15", Arch: "x86_64", HasDwarf: true},
+	}, slices)
+}
+
+func TestGetMachoSlicesFatBinary(t *testing.T) {
+	t.Log("Testing reading every slice of a universal Mach-O file")
+
+	path := writeFatBinary(t, []uint32{0x01000007, 0x0100000c}, x86Dwarf, arm64Dwarf)
+	slices, err := ios.GetMachoSlices(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ios.MachoSlice{
+		{UUID: "3ADB330A-1C19-3B98-A531-D9E09FAA3A15", Arch: "x86_64", HasDwarf: true},
+		{UUID: "6B834217-4137-305C-A70A-A6BC2686D203", Arch: "arm64", HasDwarf: true},
+	}, slices)
+
+	uuid, err := ios.GetMachoUuid(path, "arm64")
+	assert.NoError(t, err)
+	assert.Equal(t, "6B834217-4137-305C-A70A-A6BC2686D203", uuid)
+
+	_, err = ios.GetMachoUuid(path, "armv7")
+	assert.Error(t, err)
+}
+
+func TestGetMachoSlicesNotMacho(t *testing.T) {
+	t.Log("Testing that a file which is not Mach-O returns an error")
+
+	_, err := ios.GetMachoSlices("../testdata/android/AndroidManifest.xml")
+
+	assert.Error(t, err)
+}
+
+func TestFindDsymsInPath(t *testing.T) {
+	t.Log("Testing finding dSYMs and reading their DWARF information")
+
+	dwarfInfo, _, err := ios.FindDsymsInPath("../testdata/ios/dsym-test-fixtures/dsyms", false, false, log.NewLoggerWrapper("debug", log.OutputText))
+
+	assert.NoError(t, err)
+	assert.Len(t, dwarfInfo, 2)
+	for _, info := range dwarfInfo {
+		assert.Equal(t, "3ADB330A-1C19-3B98-A531-D9E09FAA3A15", info.UUID)
+		assert.Equal(t, "x86_64", info.Arch)
+		assert.Equal(t, "app", info.Name)
+	}
+}
@@ -0,0 +1,138 @@
+package mockserver_testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, responses *mockserver.Responses) (*httptest.Server, string) {
+	outputDir := t.TempDir()
+	server := httptest.NewServer(mockserver.New(outputDir, responses, 30, log.NewLoggerWrapper("debug", log.OutputText)))
+	t.Cleanup(server.Close)
+	return server, outputDir
+}
+
+func postMultipart(t *testing.T, url string, fields map[string]string, fileField string, fileName string, contents string) *http.Response {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	part, err := writer.CreateFormFile(fileField, fileName)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	response, err := http.Post(url, writer.FormDataContentType(), body)
+	require.NoError(t, err)
+	response.Body.Close()
+	return response
+}
+
+func readRecord(t *testing.T, path string) mockserver.RecordedRequest {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var record mockserver.RecordedRequest
+	require.NoError(t, json.Unmarshal(data, &record))
+	return record
+}
+
+func TestMockServerRecordsMultipartUpload(t *testing.T) {
+	t.Log("Testing that the mock server records the fields and files of an upload")
+
+	server, outputDir := newServer(t, nil)
+	response := postMultipart(t, server.URL+"/ndk-symbol", map[string]string{"apiKey": "1234", "sharedObjectName": "libapp.so"}, "soFile", "libapp.so", "symbols")
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+	record := readRecord(t, filepath.Join(outputDir, "0001-ndk-symbol", "request.json"))
+	assert.Equal(t, "/ndk-symbol", record.Path)
+	assert.Equal(t, http.StatusOK, record.Status)
+	assert.Equal(t, map[string][]string{"apiKey": {"1234"}, "sharedObjectName": {"libapp.so"}}, record.Fields)
+	require.Len(t, record.Files, 1)
+	assert.Equal(t, "soFile", record.Files[0].Field)
+	assert.Equal(t, "libapp.so", record.Files[0].FileName)
+	assert.Equal(t, int64(7), record.Files[0].Size)
+
+	contents, err := os.ReadFile(record.Files[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, "symbols", string(contents))
+}
+
+func TestMockServerRecordsBuild(t *testing.T) {
+	t.Log("Testing that the mock server records JSON build requests")
+
+	server, outputDir := newServer(t, nil)
+	response, err := http.Post(server.URL+"/", "application/json", bytes.NewBufferString(`{"apiKey":"1234","appVersion":"1.0.0"}`))
+	require.NoError(t, err)
+	response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	record := readRecord(t, filepath.Join(outputDir, "0001-root", "request.json"))
+	assert.JSONEq(t, `{"apiKey":"1234","appVersion":"1.0.0"}`, string(record.Body))
+	assert.FileExists(t, filepath.Join(outputDir, "0001-root", "body.json"))
+}
+
+func TestMockServerScriptedResponses(t *testing.T) {
+	t.Log("Testing that the mock server returns scripted status codes in turn")
+
+	responses, err := mockserver.ParseResponses([]string{"/dsym=429,503", "/proguard=409"})
+	require.NoError(t, err)
+	server, outputDir := newServer(t, responses)
+
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		response := postMultipart(t, server.URL+"/dsym", nil, "dsym", "App", "dwarf")
+		statuses = append(statuses, response.StatusCode)
+		if response.StatusCode != http.StatusOK {
+			assert.Equal(t, "30", response.Header.Get("Retry-After"))
+		}
+	}
+	assert.Equal(t, []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK}, statuses)
+
+	response := postMultipart(t, server.URL+"/proguard", nil, "proguard", "mapping.txt", "mapping")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Empty(t, response.Header.Get("Retry-After"))
+
+	record := readRecord(t, filepath.Join(outputDir, "0004-proguard", "request.json"))
+	assert.Equal(t, http.StatusConflict, record.Status)
+}
+
+func TestMockServerUnknownEndpoint(t *testing.T) {
+	t.Log("Testing that the mock server responds with 404 for unknown endpoints")
+
+	server, outputDir := newServer(t, nil)
+	response := postMultipart(t, server.URL+"/unknown", nil, "file", "file.txt", "contents")
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	record := readRecord(t, filepath.Join(outputDir, "0001-unknown", "request.json"))
+	assert.Equal(t, http.StatusNotFound, record.Status)
+	assert.Empty(t, record.Files)
+}
+
+func TestParseResponses(t *testing.T) {
+	t.Log("Testing parsing scripted responses")
+
+	_, err := mockserver.ParseResponses([]string{"/dsym=500,502"})
+	assert.NoError(t, err)
+
+	for _, spec := range []string{"/dsym", "dsym=500", "/dsym=", "/dsym=abc", "/dsym=99", "/dsym=600"} {
+		_, err := mockserver.ParseResponses([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,120 @@
+package proguard_testing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/proguard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const r8Mapping = `# compiler: R8
+# {"id":"com.android.tools.r8.mapping","version":"2.2"}
+com.example.Main -> a.a:
+# {"id":"sourceFile","fileName":"Main.kt"}
+    java.lang.String name -> a
+    # {"id":"com.android.tools.r8.synthesized"}
+    1:3:void helper(int):42:44 -> a
+    1:3:void run():10 -> a
+    4:4:void com.example.Util.format(java.lang.String):7:7 -> a
+    4:4:void run():11 -> a
+    5:5:void lambda$run$0():20:20 -> a
+    # {"id":"com.android.tools.r8.synthesized"}
+    5:5:void run():12 -> a
+    void overloaded(int) -> b
+    void overloaded(java.lang.String) -> b
+    void other() -> b
+com.example.Util -> a.b:
+# {"id":"sourceFile","fileName":"Util.kt"}
+    void format(java.lang.String) -> a
+com.example.Main$Inner -> a.c:
+    6:8:void inner() -> a
+`
+
+func parseMapping(t *testing.T, contents string) *proguard.Mapping {
+	mapping, err := proguard.ParseMapping(strings.NewReader(contents))
+	require.NoError(t, err)
+	return mapping
+}
+
+func TestRetraceInlinedFrames(t *testing.T) {
+	t.Log("Testing that inlined methods are expanded using their original line ranges and source files")
+
+	mapping := parseMapping(t, r8Mapping)
+
+	assert.Equal(t, [][]proguard.Frame{{
+		{Class: "com.example.Main", Method: "helper", File: "Main.kt", Line: 43},
+		{Class: "com.example.Main", Method: "run", File: "Main.kt", Line: 10},
+	}}, mapping.Retrace("a.a", "a", 2))
+
+	assert.Equal(t, [][]proguard.Frame{{
+		{Class: "com.example.Util", Method: "format", File: "Util.kt", Line: 7},
+		{Class: "com.example.Main", Method: "run", File: "Main.kt", Line: 11},
+	}}, mapping.Retrace("a.a", "a", 4))
+}
+
+func TestRetraceSynthesizedFrames(t *testing.T) {
+	t.Log("Testing that synthesized methods are removed from inlined frames")
+
+	mapping := parseMapping(t, r8Mapping)
+
+	assert.Equal(t, [][]proguard.Frame{{
+		{Class: "com.example.Main", Method: "run", File: "Main.kt", Line: 12},
+	}}, mapping.Retrace("a.a", "a", 5))
+}
+
+func TestRetraceAmbiguousFrames(t *testing.T) {
+	t.Log("Testing that methods sharing an obfuscated name are returned as alternatives")
+
+	mapping := parseMapping(t, r8Mapping)
+
+	assert.Equal(t, [][]proguard.Frame{
+		{{Class: "com.example.Main", Method: "overloaded", File: "Main.kt"}},
+		{{Class: "com.example.Main", Method: "other", File: "Main.kt"}},
+	}, mapping.Retrace("a.a", "b", 0))
+}
+
+func TestRetraceUnchangedLines(t *testing.T) {
+	t.Log("Testing that line numbers are kept when there is no original range, and file names default to the outer class")
+
+	mapping := parseMapping(t, r8Mapping)
+
+	assert.Equal(t, [][]proguard.Frame{{
+		{Class: "com.example.Main$Inner", Method: "inner", File: "Main.java", Line: 7},
+	}}, mapping.Retrace("a.c", "a", 7))
+	assert.Nil(t, mapping.Retrace("a.z", "a", 1))
+	assert.Empty(t, mapping.Retrace("a.c", "z", 1))
+}
+
+func TestOriginalClassName(t *testing.T) {
+	t.Log("Testing deobfuscating class names")
+
+	mapping := parseMapping(t, r8Mapping)
+
+	assert.Equal(t, "com.example.Util", mapping.OriginalClassName("a.b"))
+	assert.Equal(t, "java.lang.String", mapping.OriginalClassName("java.lang.String"))
+}
+
+func TestParseMappingFile(t *testing.T) {
+	t.Log("Testing parsing a Proguard mapping file")
+
+	mapping, err := proguard.ParseMappingFile("../../features/android/fixtures/app/build/outputs/mapping/release/mapping.txt")
+	require.NoError(t, err)
+
+	// Line 40 is within the ranges of two methods renamed to a
+	assert.Equal(t, [][]proguard.Frame{
+		{{Class: "com.bugsnag.android.AppData", Method: "toStream", File: "AppData.java", Line: 40}},
+		{{Class: "com.bugsnag.android.AppData", Method: "copy", File: "AppData.java", Line: 40}},
+	}, mapping.Retrace("com.bugsnag.android.a", "a", 40))
+}
+
+func TestParseMappingInvalid(t *testing.T) {
+	t.Log("Testing that invalid mapping files return an error")
+
+	_, err := proguard.ParseMapping(strings.NewReader("    void run() -> a\n"))
+	assert.Error(t, err)
+
+	_, err = proguard.ParseMapping(strings.NewReader("com.example.Main -> a.a:\n    not a mapping\n"))
+	assert.Error(t, err)
+}
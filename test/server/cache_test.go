@@ -0,0 +1,115 @@
+package server_testing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessFileRequestSkipsCachedUploads(t *testing.T) {
+	t.Log("Testing that files which have already been uploaded are skipped")
+
+	filePath := filepath.Join(t.TempDir(), "libexample.so")
+	assert.NoError(t, os.WriteFile(filePath, []byte("symbols"), 0644))
+
+	requests := 0
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+	opts.Upload.CacheDir = t.TempDir()
+
+	upload := func(fields map[string]string, opts options.CLI) (*recordingLogger, error) {
+		logger := &recordingLogger{}
+		err := server.ProcessFileRequest(
+			"1234567890ABCDEF1234567890ABCDEF",
+			"/ndk-symbol",
+			fields,
+			map[string]server.FileField{"soFile": server.LocalFile(filePath)},
+			filePath,
+			opts,
+			logger,
+		)
+		return logger, err
+	}
+
+	// A failed upload is not recorded
+	status = http.StatusInternalServerError
+	_, err := upload(map[string]string{"appId": "com.example"}, opts)
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests)
+
+	status = http.StatusOK
+	_, err = upload(map[string]string{"appId": "com.example"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// The same file and fields are skipped
+	logger, err := upload(map[string]string{"appId": "com.example"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, log.FileSkipped, logger.results[0].Status)
+	assert.Equal(t, "already uploaded", logger.results[0].Reason)
+
+	// Different fields are uploaded
+	_, err = upload(map[string]string{"appId": "com.example.other"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+
+	// Changed contents are uploaded
+	assert.NoError(t, os.WriteFile(filePath, []byte("new symbols"), 0644))
+	_, err = upload(map[string]string{"appId": "com.example"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, requests)
+
+	// Uploads that overwrite existing files are always sent
+	_, err = upload(map[string]string{"appId": "com.example", "overwrite": "true"}, opts)
+	assert.NoError(t, err)
+	_, err = upload(map[string]string{"appId": "com.example", "overwrite": "true"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, requests)
+
+	// Uploads are not skipped with --no-cache
+	opts.Upload.NoCache = true
+	_, err = upload(map[string]string{"appId": "com.example"}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, requests)
+}
+
+func TestProcessFileRequestDryRunDoesNotUseCache(t *testing.T) {
+	t.Log("Testing that the upload cache is not created or read for a dry run")
+
+	filePath := filepath.Join(t.TempDir(), "libexample.so")
+	assert.NoError(t, os.WriteFile(filePath, []byte("symbols"), 0644))
+
+	opts := options.CLI{}
+	opts.DryRun = true
+	opts.Upload.CacheDir = filepath.Join(t.TempDir(), "uploads")
+
+	logger := &recordingLogger{}
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/ndk-symbol",
+		map[string]string{"appId": "com.example"},
+		map[string]server.FileField{"soFile": server.LocalFile(filePath)},
+		filePath,
+		opts,
+		logger,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, log.FileDryRun, logger.results[0].Status)
+	assert.NoDirExists(t, opts.Upload.CacheDir)
+}
@@ -0,0 +1,22 @@
+package server_testing
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain points the user cache directory at a temporary directory, so that uploads
+// made by the tests are not recorded in, or skipped because of, the real upload cache.
+func TestMain(m *testing.M) {
+	cacheDir, err := os.MkdirTemp("", "bugsnag-cli-cache-*")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+	os.Setenv("HOME", cacheDir)
+
+	code := m.Run()
+
+	os.RemoveAll(cacheDir)
+	os.Exit(code)
+}
@@ -0,0 +1,144 @@
+package server_testing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageAndReplayRequests(t *testing.T) {
+	t.Log("Testing that packaged requests are uploaded later exactly as they would have been sent")
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "libexample.so")
+	require.NoError(t, os.WriteFile(filePath, []byte("symbol data"), 0644))
+	archivePath := filepath.Join(dir, "symbols.zip")
+
+	type receivedRequest struct {
+		path     string
+		fields   map[string][]string
+		fileName string
+		contents string
+	}
+	var received []receivedRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		for key := range r.MultipartForm.File {
+			file, header, err := r.FormFile(key)
+			assert.NoError(t, err)
+			contents, _ := io.ReadAll(file)
+			received = append(received, receivedRequest{r.URL.Path, r.MultipartForm.Value, header.Filename, string(contents)})
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+
+	packager, err := server.StartPackage(archivePath)
+	require.NoError(t, err)
+
+	logger := &recordingLogger{}
+	assert.NoError(t, server.ProcessFileRequest("1234567890ABCDEF1234567890ABCDEF", "/ndk-symbol", map[string]string{"appId": "com.example"},
+		map[string]server.FileField{"soFile": server.LocalFile(filePath)}, filePath, opts, logger))
+	assert.NoError(t, server.ProcessFileRequest("1234567890ABCDEF1234567890ABCDEF", "/sourcemap", map[string]string{"appVersion": "1.0"},
+		map[string]server.FileField{"sourceMap": server.InMemoryFile{Path: "dist/main.js.map", Data: []byte("{}")}}, "dist/main.js.map", opts, logger))
+
+	// Nothing is sent while packaging
+	assert.Empty(t, received)
+	assert.Equal(t, log.FilePackaged, logger.results[0].Status)
+	assert.Equal(t, 2, packager.Requests())
+	require.NoError(t, packager.Close())
+
+	// Requests are sent again once the packager is closed
+	reader, err := server.OpenPackage(archivePath)
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Len(t, reader.Manifest.Requests, 2)
+
+	for _, request := range reader.Manifest.Requests {
+		fileFieldData, err := reader.FileFields(request)
+		require.NoError(t, err)
+		// The API key is not stored in the symbol bundle
+		assert.NotContains(t, request.Fields, "apiKey")
+		assert.Equal(t, "apiKey", request.ApiKeyField)
+		assert.NoError(t, server.ProcessFileRequest("FEDCBA0987654321FEDCBA0987654321", request.Endpoint, request.Fields, fileFieldData, request.FileName, opts, &recordingLogger{}))
+	}
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "/ndk-symbol", received[0].path)
+	assert.Equal(t, []string{"com.example"}, received[0].fields["appId"])
+	assert.Equal(t, []string{"FEDCBA0987654321FEDCBA0987654321"}, received[0].fields["apiKey"])
+	assert.Equal(t, "libexample.so", received[0].fileName)
+	assert.Equal(t, "symbol data", received[0].contents)
+	assert.Equal(t, "/sourcemap", received[1].path)
+	assert.Equal(t, []string{"1.0"}, received[1].fields["appVersion"])
+	assert.Equal(t, "main.js.map", received[1].fileName)
+	assert.Equal(t, "{}", received[1].contents)
+}
+
+func TestPackagedRequestQueryApiKey(t *testing.T) {
+	t.Log("Testing that an API key in the endpoint query is not stored in the symbol bundle, and is replaced when uploaded")
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "libexample.sym")
+	require.NoError(t, os.WriteFile(filePath, []byte("MODULE Linux x86_64 ABCDEF libexample.so"), 0644))
+	archivePath := filepath.Join(dir, "symbols.zip")
+
+	var received *url.URL
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+
+	packager, err := server.StartPackage(archivePath)
+	require.NoError(t, err)
+	assert.NoError(t, server.ProcessFileRequest("1234567890ABCDEF1234567890ABCDEF", "/breakpad-symbol?api_key=1234567890ABCDEF1234567890ABCDEF&overwrite=true&project_root=/src", nil,
+		map[string]server.FileField{"symbol_file": server.LocalFile(filePath)}, filePath, opts, &recordingLogger{}))
+	require.NoError(t, packager.Close())
+
+	reader, err := server.OpenPackage(archivePath)
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Len(t, reader.Manifest.Requests, 1)
+
+	request := reader.Manifest.Requests[0]
+	assert.Equal(t, "/breakpad-symbol", request.Endpoint)
+	assert.Equal(t, map[string]string{"overwrite": "true", "project_root": "/src"}, request.Query)
+	assert.Equal(t, "api_key", request.ApiKeyParam)
+
+	fileFieldData, err := reader.FileFields(request)
+	require.NoError(t, err)
+	assert.NoError(t, server.ProcessFileRequest("FEDCBA0987654321FEDCBA0987654321", request.EndpointPath("FEDCBA0987654321FEDCBA0987654321"), nil,
+		fileFieldData, request.FileName, opts, &recordingLogger{}))
+
+	require.NotNil(t, received)
+	assert.Equal(t, "/breakpad-symbol", received.Path)
+	assert.Equal(t, "FEDCBA0987654321FEDCBA0987654321", received.Query().Get("api_key"))
+	assert.Equal(t, "true", received.Query().Get("overwrite"))
+	assert.Equal(t, "/src", received.Query().Get("project_root"))
+}
+
+func TestOpenPackageRejectsOtherFiles(t *testing.T) {
+	t.Log("Testing that files that are not symbol bundles are rejected")
+
+	notZip := filepath.Join(t.TempDir(), "symbols.zip")
+	require.NoError(t, os.WriteFile(notZip, []byte("not a zip"), 0644))
+	_, err := server.OpenPackage(notZip)
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package server_testing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncLogger captures the messages written to it, including those logged by the progress
+// ticker while an upload is running.
+type syncLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *syncLogger) record(msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *syncLogger) Debug(msg string)                 {}
+func (l *syncLogger) Info(msg string)                  { l.record("INFO " + msg) }
+func (l *syncLogger) Warn(msg string)                  { l.record("WARN " + msg) }
+func (l *syncLogger) Error(msg string)                 { l.record("ERROR " + msg) }
+func (l *syncLogger) Fatal(msg string)                 { l.record("FATAL " + msg) }
+func (l *syncLogger) ReportFile(result log.FileResult) {}
+
+func (l *syncLogger) matching(pattern string) []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var matches []string
+	for _, msg := range l.messages {
+		if regexp.MustCompile(pattern).MatchString(msg) {
+			matches = append(matches, msg)
+		}
+	}
+	return matches
+}
+
+func TestProcessFileRequestLogsProgress(t *testing.T) {
+	t.Log("Testing that the progress of a slow upload is logged periodically")
+
+	server.ConfigureProgress(true, 100*time.Millisecond)
+	defer server.ConfigureProgress(false, 0)
+
+	filePath := filepath.Join(t.TempDir(), "libil2cpp.so")
+	fileContents := bytes.Repeat([]byte("symbols"), 100000)
+	assert.NoError(t, os.WriteFile(filePath, fileContents, 0644))
+
+	var received []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		file, _, err := r.FormFile("soFile")
+		assert.NoError(t, err)
+		received, _ = io.ReadAll(file)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+
+	logger := &syncLogger{}
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/ndk-symbol",
+		nil,
+		map[string]server.FileField{"soFile": server.LocalFile(filePath)},
+		filePath,
+		opts,
+		logger,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fileContents, received)
+	assert.NotEmpty(t, logger.matching(`^INFO Uploading libil2cpp\.so: \d+% of 700\.\d kB sent \(.+/s, about .+ remaining\)$`))
+}
+
+func TestRunUploadJobsCountsFilesDone(t *testing.T) {
+	t.Log("Testing that the number of files done is logged as upload jobs finish")
+
+	server.ConfigureProgress(true, time.Minute)
+	defer server.ConfigureProgress(false, 0)
+
+	var jobs []server.UploadJob
+	for i := 0; i < 3; i++ {
+		jobs = append(jobs, server.UploadJob{
+			Name: fmt.Sprintf("file-%d", i),
+			Run: func(logger log.Logger) error {
+				logger.Info(fmt.Sprintf("upload %d", i))
+				return nil
+			},
+		})
+	}
+
+	logger := &syncLogger{}
+	assert.NoError(t, server.RunUploadJobs(jobs, 2, logger))
+	assert.Equal(t, []string{
+		"INFO upload 0", "INFO 1 of 3 files done",
+		"INFO upload 1", "INFO 2 of 3 files done",
+		"INFO upload 2", "INFO 3 of 3 files done",
+	}, logger.messages)
+}
+
+func TestRunUploadJobsWithoutProgress(t *testing.T) {
+	t.Log("Testing that the number of files done is not logged when progress is disabled")
+
+	server.ConfigureProgress(false, 0)
+
+	jobs := []server.UploadJob{
+		{Name: "a", Run: func(logger log.Logger) error { return nil }},
+		{Name: "b", Run: func(logger log.Logger) error { return nil }},
+	}
+
+	logger := &syncLogger{}
+	assert.NoError(t, server.RunUploadJobs(jobs, 1, logger))
+	assert.Empty(t, logger.messages)
+}
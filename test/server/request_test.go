@@ -0,0 +1,428 @@
+package server_testing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessFileRequestStreamsMultipartBody(t *testing.T) {
+	t.Log("Testing that file uploads are streamed with a correct Content-Length")
+
+	filePath := filepath.Join(t.TempDir(), "libexample.so")
+	fileContents := []byte("symbol data for upload")
+	assert.NoError(t, os.WriteFile(filePath, fileContents, 0644))
+
+	var (
+		requests      int
+		contentLength int64
+		bodyLength    int
+		fields        map[string][]string
+		received      []byte
+		receivedName  string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		contentLength = r.ContentLength
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		bodyLength = len(body)
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		fields = r.MultipartForm.Value
+
+		file, header, err := r.FormFile("soFile")
+		assert.NoError(t, err)
+		received, _ = io.ReadAll(file)
+		receivedName = header.Filename
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/ndk-symbol",
+		map[string]string{"appId": "com.example"},
+		map[string]server.FileField{"soFile": server.LocalFile(filePath)},
+		filePath,
+		opts,
+		&recordingLogger{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, int64(bodyLength), contentLength)
+	assert.Equal(t, fileContents, received)
+	assert.Equal(t, "libexample.so", receivedName)
+	assert.Equal(t, []string{"com.example"}, fields["appId"])
+	assert.Equal(t, []string{"1234567890ABCDEF1234567890ABCDEF"}, fields["apiKey"])
+}
+
+func TestProcessFileRequestMissingFile(t *testing.T) {
+	t.Log("Testing that a missing file fails before a request is sent")
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/ndk-symbol",
+		map[string]string{},
+		map[string]server.FileField{"soFile": server.LocalFile(filepath.Join(t.TempDir(), "missing.so"))},
+		"missing.so",
+		opts,
+		&recordingLogger{},
+	)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, requests)
+}
+
+func TestProcessFileRequestRetryResendsFullBody(t *testing.T) {
+	t.Log("Testing that each retry attempt streams the complete file again")
+
+	filePath := filepath.Join(t.TempDir(), "app.dSYM.zip")
+	fileContents := []byte("dsym contents")
+	assert.NoError(t, os.WriteFile(filePath, fileContents, 0644))
+
+	var received [][]byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("dsym")
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(file)
+		received = append(received, data)
+
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+	opts.Upload.Retries = 1
+	opts.Upload.RetryBackoff = time.Millisecond
+
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/dsym",
+		map[string]string{},
+		map[string]server.FileField{"dsym": server.LocalFile(filePath)},
+		filePath,
+		opts,
+		&recordingLogger{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{fileContents, fileContents}, received)
+}
+
+func TestProcessFileRequestRetryClassification(t *testing.T) {
+	t.Log("Testing that only retryable failures are retried")
+
+	tests := []struct {
+		name         string
+		status       int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "Bad request is terminal", status: http.StatusBadRequest, wantRequests: 1, wantErr: true},
+		{name: "Unauthorized is terminal", status: http.StatusUnauthorized, wantRequests: 1, wantErr: true},
+		{name: "Unprocessable entity is terminal", status: http.StatusUnprocessableEntity, wantRequests: 1, wantErr: true},
+		{name: "Conflict is treated as a duplicate", status: http.StatusConflict, wantRequests: 1, wantErr: false},
+		{name: "Server error is retried", status: http.StatusBadGateway, wantRequests: 3, wantErr: true},
+		{name: "Rate limiting is retried", status: http.StatusTooManyRequests, wantRequests: 3, wantErr: true},
+	}
+
+	filePath := filepath.Join(t.TempDir(), "mapping.txt")
+	assert.NoError(t, os.WriteFile(filePath, []byte("mapping"), 0644))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			opts := options.CLI{}
+			opts.Upload.UploadAPIRootUrl = ts.URL
+			opts.Upload.Retries = 2
+			opts.Upload.RetryBackoff = time.Millisecond
+
+			err := server.ProcessFileRequest(
+				"1234567890ABCDEF1234567890ABCDEF",
+				"/proguard",
+				map[string]string{},
+				map[string]server.FileField{"proguard": server.LocalFile(filePath)},
+				filePath,
+				opts,
+				&recordingLogger{},
+			)
+
+			assert.Equal(t, tt.wantRequests, requests)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, server.IsStatus(err, tt.status))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProcessFileRequestHonoursRetryAfter(t *testing.T) {
+	t.Log("Testing that the Retry-After header sets the delay before the next attempt")
+
+	filePath := filepath.Join(t.TempDir(), "mapping.txt")
+	assert.NoError(t, os.WriteFile(filePath, []byte("mapping"), 0644))
+
+	var times []time.Time
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+	opts.Upload.Retries = 1
+	opts.Upload.RetryBackoff = time.Millisecond
+
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/proguard",
+		map[string]string{},
+		map[string]server.FileField{"proguard": server.LocalFile(filePath)},
+		filePath,
+		opts,
+		&recordingLogger{},
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, times, 2)
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), time.Second)
+}
+
+func TestProcessFileRequestLimitsRetryAfter(t *testing.T) {
+	t.Log("Testing that the Retry-After delay is limited to the maximum retry backoff")
+
+	filePath := filepath.Join(t.TempDir(), "mapping.txt")
+	assert.NoError(t, os.WriteFile(filePath, []byte("mapping"), 0644))
+
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+	opts.Upload.Retries = 1
+	opts.Upload.RetryBackoff = time.Millisecond
+	opts.Upload.RetryMaxBackoff = 10 * time.Millisecond
+
+	start := time.Now()
+	err := server.ProcessFileRequest(
+		"1234567890ABCDEF1234567890ABCDEF",
+		"/proguard",
+		map[string]string{},
+		map[string]server.FileField{"proguard": server.LocalFile(filePath)},
+		filePath,
+		opts,
+		&recordingLogger{},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestProcessFileRequestReturnsAPIError(t *testing.T) {
+	t.Log("Testing that unsuccessful responses are returned as a structured APIError")
+
+	filePath := filepath.Join(t.TempDir(), "mapping.txt")
+	assert.NoError(t, os.WriteFile(filePath, []byte("mapping"), 0644))
+
+	tests := []struct {
+		name        string
+		body        string
+		wantMessage string
+		wantErrors  []string
+	}{
+		{
+			name:        "JSON error body",
+			body:        `{"error":"Invalid mapping file","errors":["missing appId","missing versionCode"]}`,
+			wantMessage: "Invalid mapping file",
+			wantErrors:  []string{"missing appId", "missing versionCode"},
+		},
+		{
+			name: "Malformed JSON body",
+			body: `{"error":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			opts := options.CLI{}
+			opts.Upload.UploadAPIRootUrl = ts.URL
+
+			err := server.ProcessFileRequest(
+				"1234567890ABCDEF1234567890ABCDEF",
+				"/proguard",
+				map[string]string{},
+				map[string]server.FileField{"proguard": server.LocalFile(filePath)},
+				filePath,
+				opts,
+				&recordingLogger{},
+			)
+
+			apiErr, ok := server.AsAPIError(err)
+			assert.True(t, ok)
+			assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+			assert.Equal(t, ts.URL+"/proguard", apiErr.Endpoint)
+			assert.Equal(t, tt.body, apiErr.Body)
+			assert.Equal(t, tt.wantMessage, apiErr.Message)
+			assert.Equal(t, tt.wantErrors, apiErr.Errors)
+			assert.False(t, apiErr.Retryable())
+			assert.False(t, apiErr.IsDuplicate())
+		})
+	}
+}
+
+func TestProcessFileRequestReportsFileResults(t *testing.T) {
+	t.Log("Testing that the outcome of each upload is reported for the command summary")
+
+	dir := t.TempDir()
+	uploaded := filepath.Join(dir, "mapping.txt")
+	duplicate := filepath.Join(dir, "duplicate.txt")
+	excluded := filepath.Join(dir, "excluded.map")
+	for _, file := range []string{uploaded, duplicate, excluded} {
+		assert.NoError(t, os.WriteFile(file, []byte("data"), 0644))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("proguard")
+		assert.NoError(t, err)
+		file.Close()
+
+		w.Header().Set("Content-Type", "application/json")
+		if header.Filename == "duplicate.txt" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"duplicate"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"warnings":["appId does not match"]}`))
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.UploadAPIRootUrl = ts.URL
+	opts.Upload.Exclude = []string{"*.map"}
+
+	logger := &recordingLogger{}
+	for _, file := range []string{uploaded, duplicate, excluded} {
+		err := server.ProcessFileRequest(
+			"1234567890ABCDEF1234567890ABCDEF",
+			"/proguard",
+			map[string]string{"buildUUID": "build-1234"},
+			map[string]server.FileField{"proguard": server.LocalFile(file)},
+			file,
+			opts,
+			logger,
+		)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []log.FileResult{
+		{
+			File:        uploaded,
+			Status:      log.FileUploaded,
+			Endpoint:    ts.URL + "/proguard",
+			Identifiers: map[string]string{"buildUuid": "build-1234"},
+			Warnings:    []string{"appId does not match"},
+		},
+		{
+			File:        duplicate,
+			Status:      log.FileSkipped,
+			Reason:      "duplicate",
+			Endpoint:    ts.URL + "/proguard",
+			Identifiers: map[string]string{"buildUuid": "build-1234"},
+		},
+		{
+			File:        excluded,
+			Status:      log.FileSkipped,
+			Reason:      "matches exclude pattern",
+			Identifiers: map[string]string{"buildUuid": "build-1234"},
+		},
+	}, logger.results)
+}
+
+func TestProcessFileRequestReportsMissingApiKey(t *testing.T) {
+	t.Log("Testing that a file that fails because no API key was given is reported for the command summary")
+
+	opts := options.CLI{}
+	logger := &recordingLogger{}
+	err := server.ProcessFileRequest(
+		"",
+		"/proguard",
+		map[string]string{"buildUUID": "build-1234"},
+		map[string]server.FileField{"proguard": server.LocalFile("mapping.txt")},
+		"mapping.txt",
+		opts,
+		logger,
+	)
+
+	assert.ErrorContains(t, err, "missing api key")
+	assert.Equal(t, []log.FileResult{
+		{
+			File:        "mapping.txt",
+			Status:      log.FileFailed,
+			Error:       err.Error(),
+			Identifiers: map[string]string{"buildUuid": "build-1234"},
+		},
+	}, logger.results)
+}
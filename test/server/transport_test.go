@@ -0,0 +1,264 @@
+package server_testing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// writePEM writes a PEM block to a file in a temporary directory and returns its path.
+func writePEM(t *testing.T, name string, blockType string, bytes []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0600))
+	return path
+}
+
+// newClientCertificate creates a self-signed client certificate, returning the paths to the
+// certificate and key and the parsed certificate.
+func newClientCertificate(t *testing.T) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "bugsnag-cli-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return writePEM(t, "client.crt", "CERTIFICATE", der), writePEM(t, "client.key", "EC PRIVATE KEY", keyDer), certificate
+}
+
+// sendTestBuild sends a build request to the given server.
+func sendTestBuild(url string, opts options.CLI) error {
+	opts.CreateBuild.BuildApiRootUrl = url
+	return server.ProcessBuildRequest("1234567890ABCDEF1234567890ABCDEF", []byte(`{}`), opts, &recordingLogger{})
+}
+
+func TestRequestsUseProxy(t *testing.T) {
+	t.Log("Testing that requests are sent through the proxy given by --proxy")
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.Method+" "+r.URL.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	opts := options.CLI{}
+	opts.Proxy = proxy.URL
+
+	assert.NoError(t, sendTestBuild("http://build.bugsnag.invalid", opts))
+	assert.Equal(t, []string{"POST http://build.bugsnag.invalid/"}, proxied)
+}
+
+func TestRequestsTrustCaCert(t *testing.T) {
+	t.Log("Testing that a server certificate is trusted only when its CA is given by --ca-cert")
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	err := sendTestBuild(ts.URL, options.CLI{})
+	assert.ErrorContains(t, err, "certificate")
+
+	opts := options.CLI{}
+	opts.CaCert = writePEM(t, "ca.crt", "CERTIFICATE", ts.Certificate().Raw)
+	assert.NoError(t, sendTestBuild(ts.URL, opts))
+
+	opts = options.CLI{}
+	opts.InsecureSkipVerify = true
+	assert.NoError(t, sendTestBuild(ts.URL, opts))
+}
+
+func TestRequestsPresentClientCert(t *testing.T) {
+	t.Log("Testing that the client certificate given by --client-cert is presented for mutual TLS")
+
+	certPath, keyPath, certificate := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(certificate)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.CaCert = writePEM(t, "ca.crt", "CERTIFICATE", ts.Certificate().Raw)
+	assert.Error(t, sendTestBuild(ts.URL, opts))
+
+	opts.ClientCert = certPath
+	opts.ClientKey = keyPath
+	assert.NoError(t, sendTestBuild(ts.URL, opts))
+}
+
+func TestNewTransportRejectsInvalidOptions(t *testing.T) {
+	t.Log("Testing that invalid proxy and TLS options are rejected")
+
+	certPath, _, _ := newClientCertificate(t)
+
+	_, err := server.NewTransport(options.CLI{Globals: options.Globals{ClientCert: certPath}})
+	assert.ErrorContains(t, err, "--client-cert and --client-key must be used together")
+
+	_, err = server.NewTransport(options.CLI{Globals: options.Globals{Proxy: "ftp://proxy.example.com"}})
+	assert.ErrorContains(t, err, "unsupported proxy scheme")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.crt")
+	assert.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+	_, err = server.NewTransport(options.CLI{Globals: options.Globals{CaCert: notPEM}})
+	assert.ErrorContains(t, err, "no PEM certificates found")
+
+	transport, err := server.NewTransport(options.CLI{Globals: options.Globals{Proxy: "proxy.example.com:8080"}})
+	assert.NoError(t, err)
+	request, _ := http.NewRequest("POST", "https://upload.bugsnag.com", nil)
+	proxyUrl, err := transport.Proxy(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:8080", proxyUrl.String())
+}
+
+func TestRequestsReuseConnections(t *testing.T) {
+	t.Log("Testing that consecutive requests reuse the same connection")
+
+	var mutex sync.Mutex
+	connections := 0
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mutex.Lock()
+			connections++
+			mutex.Unlock()
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sendTestBuild(ts.URL, options.CLI{}))
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 1, connections)
+}
+
+func TestRequestsUseHttp2(t *testing.T) {
+	t.Log("Testing that requests only use HTTP/2 when --http2 is set")
+
+	var protocols []string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protocols = append(protocols, r.Proto)
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.InsecureSkipVerify = true
+	assert.NoError(t, sendTestBuild(ts.URL, opts))
+
+	opts.Http2 = true
+	assert.NoError(t, sendTestBuild(ts.URL, opts))
+
+	assert.Equal(t, []string{"HTTP/1.1", "HTTP/2.0"}, protocols)
+}
+
+func TestRequestsTimeOutWaitingForResponse(t *testing.T) {
+	t.Log("Testing that a request fails when the server does not respond within --response-timeout")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.ResponseTimeout = 50 * time.Millisecond
+
+	err := sendTestBuild(ts.URL, opts)
+	assert.ErrorContains(t, err, "timeout awaiting response headers")
+}
+
+// uploadTestFile uploads a file of the given size to the given server.
+func uploadTestFile(t *testing.T, url string, size int, opts options.CLI) error {
+	filePath := filepath.Join(t.TempDir(), "libexample.so")
+	assert.NoError(t, os.WriteFile(filePath, make([]byte, size), 0644))
+
+	opts.Upload.UploadAPIRootUrl = url
+	return server.ProcessFileRequest("1234567890ABCDEF1234567890ABCDEF", "/ndk-symbol", nil,
+		map[string]server.FileField{"soFile": server.LocalFile(filePath)}, filePath, opts, &recordingLogger{})
+}
+
+func TestSlowUploadsDoNotTimeOut(t *testing.T) {
+	t.Log("Testing that an upload taking longer than --timeout succeeds while the file is still being sent")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buffer := make([]byte, 256*1024)
+		for {
+			time.Sleep(100 * time.Millisecond)
+			if _, err := r.Body.Read(buffer); err != nil {
+				break
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.Upload.IdleTimeout = time.Second
+
+	start := time.Now()
+	assert.NoError(t, uploadTestFile(t, ts.URL, 4*1024*1024, opts))
+	assert.Greater(t, time.Since(start), time.Second)
+}
+
+func TestStalledUploadsTimeOut(t *testing.T) {
+	t.Log("Testing that an upload fails when none of the file is sent for --idle-timeout")
+
+	// The server never reads the request body
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	opts := options.CLI{}
+	opts.Upload.IdleTimeout = time.Second
+
+	err := uploadTestFile(t, ts.URL, 64*1024*1024, opts)
+	assert.ErrorContains(t, err, "no data was sent for 1s")
+}
@@ -0,0 +1,144 @@
+package server_testing
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger captures every message and file result written to it, in order.
+type recordingLogger struct {
+	messages []string
+	results  []log.FileResult
+}
+
+func (l *recordingLogger) Debug(msg string)                 { l.messages = append(l.messages, "DEBUG "+msg) }
+func (l *recordingLogger) Info(msg string)                  { l.messages = append(l.messages, "INFO "+msg) }
+func (l *recordingLogger) Warn(msg string)                  { l.messages = append(l.messages, "WARN "+msg) }
+func (l *recordingLogger) Error(msg string)                 { l.messages = append(l.messages, "ERROR "+msg) }
+func (l *recordingLogger) Fatal(msg string)                 { l.messages = append(l.messages, "FATAL "+msg) }
+func (l *recordingLogger) ReportFile(result log.FileResult) { l.results = append(l.results, result) }
+
+func TestRunUploadJobsKeepsLogOrder(t *testing.T) {
+	t.Log("Testing that concurrent upload output is flushed in job order")
+
+	var jobs []server.UploadJob
+	for i := 0; i < 5; i++ {
+		jobs = append(jobs, server.UploadJob{
+			Name: fmt.Sprintf("file-%d", i),
+			Run: func(logger log.Logger) error {
+				// Later jobs finish first
+				time.Sleep(time.Duration(5-i) * 5 * time.Millisecond)
+				logger.Info(fmt.Sprintf("start %d", i))
+				logger.Info(fmt.Sprintf("end %d", i))
+				return nil
+			},
+		})
+	}
+
+	logger := &recordingLogger{}
+	err := server.RunUploadJobs(jobs, 5, logger)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"INFO start 0", "INFO end 0",
+		"INFO start 1", "INFO end 1",
+		"INFO start 2", "INFO end 2",
+		"INFO start 3", "INFO end 3",
+		"INFO start 4", "INFO end 4",
+	}, logger.messages)
+}
+
+func TestRunUploadJobsLimitsConcurrency(t *testing.T) {
+	t.Log("Testing that no more than the configured number of uploads run at once")
+
+	var running, peak int32
+	var jobs []server.UploadJob
+	for i := 0; i < 10; i++ {
+		jobs = append(jobs, server.UploadJob{
+			Name: fmt.Sprintf("file-%d", i),
+			Run: func(logger log.Logger) error {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					previous := atomic.LoadInt32(&peak)
+					if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			},
+		})
+	}
+
+	err := server.RunUploadJobs(jobs, 3, &recordingLogger{})
+
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+func TestRunUploadJobsAggregatesErrors(t *testing.T) {
+	t.Log("Testing that every job runs and failures are reported together")
+
+	failure := errors.New("boom")
+	var ran int32
+	var jobs []server.UploadJob
+	for i := 0; i < 4; i++ {
+		jobs = append(jobs, server.UploadJob{
+			Name: fmt.Sprintf("file-%d", i),
+			Run: func(logger log.Logger) error {
+				atomic.AddInt32(&ran, 1)
+				if i%2 == 0 {
+					return failure
+				}
+				return nil
+			},
+		})
+	}
+
+	for _, concurrency := range []int{1, 2} {
+		ran = 0
+		err := server.RunUploadJobs(jobs, concurrency, &recordingLogger{})
+
+		var uploadErrors *server.UploadErrors
+		assert.True(t, errors.As(err, &uploadErrors))
+		assert.Equal(t, int32(4), ran)
+		assert.Equal(t, 4, uploadErrors.Total)
+		assert.Len(t, uploadErrors.Failures, 2)
+		assert.Equal(t, "file-0", uploadErrors.Failures[0].Name)
+		assert.Equal(t, "file-2", uploadErrors.Failures[1].Name)
+		assert.ErrorIs(t, err, failure)
+		assert.Contains(t, err.Error(), "2 of 4 uploads failed")
+	}
+}
+
+func TestRunUploadJobsKeepsReportOrder(t *testing.T) {
+	t.Log("Testing that file results reported by concurrent uploads are kept in job order")
+
+	var jobs []server.UploadJob
+	for i := 0; i < 3; i++ {
+		jobs = append(jobs, server.UploadJob{
+			Name: fmt.Sprintf("file-%d", i),
+			Run: func(logger log.Logger) error {
+				time.Sleep(time.Duration(3-i) * 5 * time.Millisecond)
+				log.ReportFile(logger, log.FileResult{File: fmt.Sprintf("file-%d", i), Status: log.FileUploaded})
+				return nil
+			},
+		})
+	}
+
+	logger := &recordingLogger{}
+	assert.NoError(t, server.RunUploadJobs(jobs, 3, logger))
+	assert.Equal(t, []log.FileResult{
+		{File: "file-0", Status: log.FileUploaded},
+		{File: "file-1", Status: log.FileUploaded},
+		{File: "file-2", Status: log.FileUploaded},
+	}, logger.results)
+}
@@ -0,0 +1,147 @@
+package sourcemap_testing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeSourceMap(t *testing.T, contents string) map[string]interface{} {
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(contents), &decoded))
+	return decoded
+}
+
+func assertOriginalPosition(t *testing.T, contents map[string]interface{}, line int, column int, expected sourcemap.Position) {
+	parsed, err := sourcemap.Parse(contents)
+	require.NoError(t, err)
+	position, found := parsed.OriginalPosition(line, column)
+	require.True(t, found, "%d:%d is not mapped", line, column)
+	assert.Equal(t, expected, position)
+}
+
+func TestFlattenIndexMap(t *testing.T) {
+	t.Log("Testing flattening an index map into a regular source map")
+
+	indexMap := decodeSourceMap(t, `{
+		"version": 3,
+		"file": "main.js",
+		"sections": [
+			{"offset": {"line": 0, "column": 0}, "map": {"version": 3, "sources": ["a.js"], "sourcesContent": ["a()"], "names": ["a"], "mappings": "AAAAA"}},
+			{"offset": {"line": 0, "column": 10}, "map": {"version": 3, "sources": ["b.js", "a.js"], "names": [], "mappings": "AAAA;CCCA", "ignoreList": [0]}}
+		]
+	}`)
+
+	flattened, err := sourcemap.Flatten(indexMap)
+	require.NoError(t, err)
+
+	assert.NotContains(t, flattened, "sections")
+	assert.Equal(t, "main.js", flattened["file"])
+	assert.Equal(t, []interface{}{"a.js", "b.js"}, flattened["sources"])
+	assert.Equal(t, []interface{}{"a()", nil}, flattened["sourcesContent"])
+	assert.Equal(t, []interface{}{float64(1)}, flattened["ignoreList"])
+
+	assertOriginalPosition(t, flattened, 0, 0, sourcemap.Position{Source: "a.js", Name: "a"})
+	assertOriginalPosition(t, flattened, 0, 12, sourcemap.Position{Source: "b.js"})
+	assertOriginalPosition(t, flattened, 1, 1, sourcemap.Position{Source: "a.js", Line: 1})
+
+	// Regular source maps are returned unchanged
+	regular := decodeSourceMap(t, `{"version": 3, "sources": ["a.js"], "names": [], "mappings": "AAAA"}`)
+	unchanged, err := sourcemap.Flatten(regular)
+	require.NoError(t, err)
+	assert.Equal(t, regular, unchanged)
+}
+
+func TestCompose(t *testing.T) {
+	t.Log("Testing composing the source map of a minifier with the source map of a compiler")
+
+	minified := decodeSourceMap(t, `{"version": 3, "file": "main.min.js", "sources": ["main.js", "vendor.js"], "names": ["x"], "mappings": "AAAAA,KACE,KCAA", "debugId": "3a9f776b-88cf-4c04-a55b-a416a5129a41"}`)
+	compiled := decodeSourceMap(t, `{"version": 3, "file": "main.js", "sources": ["src/main.ts"], "sourcesContent": ["// main"], "names": ["original"], "mappings": "AAUIA;AAUJ"}`)
+
+	composed, err := sourcemap.Compose([]map[string]interface{}{minified, compiled})
+	require.NoError(t, err)
+
+	assert.Equal(t, "main.min.js", composed["file"])
+	assert.Equal(t, "3a9f776b-88cf-4c04-a55b-a416a5129a41", composed["debugId"])
+	assert.Equal(t, []interface{}{"src/main.ts", "vendor.js"}, composed["sources"])
+	assert.Equal(t, []interface{}{"// main", nil}, composed["sourcesContent"])
+
+	// The name from the original source is used over the intermediate one
+	assertOriginalPosition(t, composed, 0, 0, sourcemap.Position{Source: "src/main.ts", Line: 10, Column: 4, Name: "original"})
+	assertOriginalPosition(t, composed, 0, 5, sourcemap.Position{Source: "src/main.ts", Line: 20})
+	// Sources that were not compiled are kept as they are
+	assertOriginalPosition(t, composed, 0, 10, sourcemap.Position{Source: "vendor.js", Line: 1, Column: 2})
+}
+
+func TestComposeUnmatchedSourceMap(t *testing.T) {
+	t.Log("Testing that a source map that cannot be matched to a source is an error")
+
+	minified := decodeSourceMap(t, `{"version": 3, "sources": ["main.js", "vendor.js"], "names": [], "mappings": "AAAA,KCAA"}`)
+
+	_, err := sourcemap.Compose([]map[string]interface{}{minified, decodeSourceMap(t, `{"version": 3, "sources": ["src/main.ts"], "names": [], "mappings": "AAAA"}`)})
+	assert.ErrorContains(t, err, "cannot be matched")
+
+	_, err = sourcemap.Compose([]map[string]interface{}{minified, decodeSourceMap(t, `{"version": 3, "file": "other.js", "sources": ["src/main.ts"], "names": [], "mappings": "AAAA"}`)})
+	assert.ErrorContains(t, err, "cannot be matched")
+
+	// A source map for the only source that maps none of the positions used is not the right one
+	_, err = sourcemap.Compose([]map[string]interface{}{
+		decodeSourceMap(t, `{"version": 3, "sources": ["main.js"], "names": [], "mappings": "AAKA"}`),
+		decodeSourceMap(t, `{"version": 3, "file": "other.js", "sources": ["src/main.ts"], "names": [], "mappings": "AAAA"}`),
+	})
+	assert.ErrorContains(t, err, "could be traced")
+}
+
+func TestComposeMatchesSourceByPath(t *testing.T) {
+	t.Log("Testing that a source map is only traced through the source whose path matches its file field")
+
+	minified := decodeSourceMap(t, `{"version": 3, "sources": ["src/a/index.js", "src/b/index.js"], "names": [], "mappings": "AAAA,KCAA"}`)
+
+	// The path of the file ends with the path of one of the sources
+	composed, err := sourcemap.Compose([]map[string]interface{}{minified, decodeSourceMap(t, `{"version": 3, "file": "build/src/a/index.js", "sources": ["src/a/index.ts"], "names": [], "mappings": "AAAA"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"src/a/index.ts", "src/b/index.js"}, composed["sources"])
+	assertOriginalPosition(t, composed, 0, 0, sourcemap.Position{Source: "src/a/index.ts"})
+	assertOriginalPosition(t, composed, 0, 5, sourcemap.Position{Source: "src/b/index.js"})
+
+	// A base name shared by more than one source does not pick one of them
+	_, err = sourcemap.Compose([]map[string]interface{}{minified, decodeSourceMap(t, `{"version": 3, "file": "index.js", "sources": ["src/c/index.ts"], "names": [], "mappings": "AAAA"}`)})
+	assert.ErrorContains(t, err, "cannot be matched")
+}
+
+func TestComposeMetroMetadata(t *testing.T) {
+	t.Log("Testing that Metro source metadata and Hermes function offsets are kept when composing")
+
+	compiled := decodeSourceMap(t, `{"version": 3, "sources": ["index.android.bundle"], "names": [], "mappings": "AAAA,EACA", "x_hermes_function_offsets": {"0": [0, 2]}}`)
+	packaged := decodeSourceMap(t, `{"version": 3, "sources": ["/app/index.js", "/app/App.js"], "names": [], "mappings": "AAAA;ACAA", "x_facebook_sources": [null, [{"names": ["<global>", "App"], "mappings": "AAA,CCC"}]]}`)
+
+	composed, err := sourcemap.Compose([]map[string]interface{}{compiled, packaged})
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{"/app/index.js", "/app/App.js"}, composed["sources"])
+	assert.Equal(t, compiled["x_hermes_function_offsets"], composed["x_hermes_function_offsets"])
+	assert.Equal(t, packaged["x_facebook_sources"], composed["x_facebook_sources"])
+	assertOriginalPosition(t, composed, 0, 2, sourcemap.Position{Source: "/app/App.js"})
+}
+
+func TestComposeFiles(t *testing.T) {
+	t.Log("Testing that composed sources are relative to the directory of the first source map")
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dist"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "build"), 0755))
+	minifiedPath := filepath.Join(dir, "dist", "main.min.js.map")
+	compiledPath := filepath.Join(dir, "build", "main.js.map")
+	require.NoError(t, os.WriteFile(minifiedPath, []byte(`{"version": 3, "sources": ["../build/main.js"], "names": [], "mappings": "AAAA"}`), 0644))
+	require.NoError(t, os.WriteFile(compiledPath, []byte(`{"version": 3, "file": "main.js", "sourceRoot": "../src", "sources": ["main.ts"], "names": [], "mappings": "AAAA"}`), 0644))
+
+	composed, err := sourcemap.ComposeFiles([]string{minifiedPath, compiledPath})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"../src/main.ts"}, composed["sources"])
+	assert.NotContains(t, composed, "sourceRoot")
+}
@@ -0,0 +1,58 @@
+package sourcemap_testing
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDebugId(t *testing.T) {
+	t.Log("Testing that debug IDs are version 4 UUIDs derived from the bundle")
+
+	debugId := sourcemap.GenerateDebugId([]byte("console.log(1)"))
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, debugId)
+	assert.Equal(t, debugId, sourcemap.GenerateDebugId([]byte("console.log(1)")))
+	assert.NotEqual(t, debugId, sourcemap.GenerateDebugId([]byte("console.log(2)")))
+}
+
+func TestInjectBundleDebugId(t *testing.T) {
+	t.Log("Testing that the debug ID is injected before the sourceMappingURL comment")
+
+	const debugId = "3a9f776b-88cf-4c04-a55b-a416a5129a41"
+	bundle := sourcemap.InjectBundleDebugId([]byte("foo();\n//# sourceMappingURL=main.js.map\n"), debugId)
+
+	lines := strings.Split(string(bundle), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "foo();", lines[0])
+	assert.Contains(t, lines[1], `_debugIds[n]="`+debugId+`"`)
+	assert.Equal(t, "//# debugId="+debugId, lines[2])
+	assert.Equal(t, "//# sourceMappingURL=main.js.map", lines[3])
+	assert.Equal(t, debugId, sourcemap.BundleDebugId(bundle))
+
+	// Bundles without a sourceMappingURL comment or trailing newline have it appended
+	bundle = sourcemap.InjectBundleDebugId([]byte("foo();"), debugId)
+	assert.Equal(t, debugId, sourcemap.BundleDebugId(bundle))
+	assert.Equal(t, "foo();\n", string(bundle[:7]))
+}
+
+func TestInjectSourceMapDebugId(t *testing.T) {
+	t.Log("Testing that the debug ID is added to a source map without changing the rest of it")
+
+	const debugId = "3a9f776b-88cf-4c04-a55b-a416a5129a41"
+	sourceMap, err := sourcemap.InjectSourceMapDebugId([]byte(`{"version": 3, "mappings": ""}`), debugId)
+	require.NoError(t, err)
+	assert.Equal(t, `{"debugId":"`+debugId+`","version": 3, "mappings": ""}`, string(sourceMap))
+
+	sourceMap, err = sourcemap.InjectSourceMapDebugId([]byte(`{"debugId": "old", "version": 3}`), debugId)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(sourceMap, &decoded))
+	assert.Equal(t, debugId, sourcemap.SourceMapDebugId(decoded))
+
+	_, err = sourcemap.InjectSourceMapDebugId([]byte(`[]`), debugId)
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package sourcemap_testing
+
+import (
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeMappings(t *testing.T) {
+	t.Log("Testing decoding VLQ mappings into segments")
+
+	segments, err := sourcemap.DecodeMappings("AAAAA;;AACA,EAAEC,G")
+
+	require.NoError(t, err)
+	assert.Equal(t, []sourcemap.Segment{
+		{GeneratedLine: 0, GeneratedColumn: 0, HasSource: true, HasName: true},
+		{GeneratedLine: 2, GeneratedColumn: 0, HasSource: true, OriginalLine: 1},
+		{GeneratedLine: 2, GeneratedColumn: 2, HasSource: true, OriginalLine: 1, OriginalColumn: 2, HasName: true, NameIndex: 1},
+		{GeneratedLine: 2, GeneratedColumn: 5},
+	}, segments)
+}
+
+func TestDecodeMappingsLargeAndNegativeValues(t *testing.T) {
+	t.Log("Testing decoding multi-digit and negative VLQ values")
+
+	// w+B is 1000, C is 1 and D is -1
+	segments, err := sourcemap.DecodeMappings("w+BAw+BC,CADD")
+
+	require.NoError(t, err)
+	assert.Equal(t, []sourcemap.Segment{
+		{GeneratedLine: 0, GeneratedColumn: 1000, HasSource: true, OriginalLine: 1000, OriginalColumn: 1},
+		{GeneratedLine: 0, GeneratedColumn: 1001, HasSource: true, OriginalLine: 999, OriginalColumn: 0},
+	}, segments)
+}
+
+func TestDecodeMappingsInvalid(t *testing.T) {
+	t.Log("Testing that invalid mappings return an error")
+
+	for _, mappings := range []string{
+		"AA!A",  // invalid base64 character
+		"AAAg",  // incomplete value
+		"AA",    // wrong number of fields
+		"AAAA,", // empty segment
+		"DAAA",  // negative column
+	} {
+		_, err := sourcemap.DecodeMappings(mappings)
+		assert.Error(t, err, mappings)
+	}
+}
+
+func TestEncodeMappings(t *testing.T) {
+	t.Log("Testing that encoded mappings decode to the same segments")
+
+	for _, mappings := range []string{"AAAAA;;AACA,EAAEC,G", "w+BAw+BC,CADD"} {
+		segments, err := sourcemap.DecodeMappings(mappings)
+		require.NoError(t, err)
+		assert.Equal(t, mappings, sourcemap.EncodeMappings(segments))
+	}
+
+	// Segments are sorted by their generated position before they are encoded
+	assert.Equal(t, "AAAA,CAAC;CACA", sourcemap.EncodeMappings([]sourcemap.Segment{
+		{GeneratedLine: 1, GeneratedColumn: 1, HasSource: true, OriginalLine: 1, OriginalColumn: 1},
+		{GeneratedLine: 0, GeneratedColumn: 0, HasSource: true},
+		{GeneratedLine: 0, GeneratedColumn: 1, HasSource: true, OriginalColumn: 1},
+	}))
+}
@@ -0,0 +1,84 @@
+package sourcemap_testing
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/sourcemap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseSourceMap(t *testing.T, contents string) *sourcemap.SourceMap {
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(contents), &decoded))
+
+	sourceMap, err := sourcemap.Parse(decoded)
+	require.NoError(t, err)
+	return sourceMap
+}
+
+func TestOriginalPosition(t *testing.T) {
+	t.Log("Testing looking up the original position of a generated position")
+
+	sourceMap := parseSourceMap(t, `{
+		"version": 3,
+		"sourceRoot": "src/",
+		"sources": ["a.js", "b.js"],
+		"names": ["foo"],
+		"mappings": "AAAA,IAAIA;ACCA,E"
+	}`)
+
+	position, found := sourceMap.OriginalPosition(0, 6)
+	assert.True(t, found)
+	assert.Equal(t, sourcemap.Position{Source: "src/a.js", Line: 0, Column: 4, Name: "foo"}, position)
+
+	position, found = sourceMap.OriginalPosition(1, 0)
+	assert.True(t, found)
+	assert.Equal(t, sourcemap.Position{Source: "src/b.js", Line: 1, Column: 4}, position)
+
+	// The segment at column 2 has no source
+	_, found = sourceMap.OriginalPosition(1, 3)
+	assert.False(t, found)
+
+	_, found = sourceMap.OriginalPosition(2, 0)
+	assert.False(t, found)
+}
+
+func TestOriginalPositionIndexMap(t *testing.T) {
+	t.Log("Testing looking up original positions in the sections of an index map")
+
+	sourceMap := parseSourceMap(t, `{
+		"version": 3,
+		"sections": [
+			{"offset": {"line": 1, "column": 10}, "map": {"version": 3, "sources": ["second.js"], "names": [], "mappings": "AAKA"}},
+			{"offset": {"line": 0, "column": 0}, "map": {"version": 3, "sources": ["first.js"], "names": [], "mappings": "AAAA;AACA"}}
+		]
+	}`)
+
+	position, found := sourceMap.OriginalPosition(0, 5)
+	assert.True(t, found)
+	assert.Equal(t, "first.js", position.Source)
+
+	// Before the start of the second section on the same line
+	position, found = sourceMap.OriginalPosition(1, 5)
+	assert.True(t, found)
+	assert.Equal(t, sourcemap.Position{Source: "first.js", Line: 1}, position)
+
+	position, found = sourceMap.OriginalPosition(1, 12)
+	assert.True(t, found)
+	assert.Equal(t, sourcemap.Position{Source: "second.js", Line: 5}, position)
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Log("Testing that source maps without sources or valid mappings return an error")
+
+	for _, contents := range []map[string]interface{}{
+		{"mappings": "AAAA"},
+		{"sources": []interface{}{"a.js"}, "mappings": "!"},
+		{"sections": []interface{}{map[string]interface{}{"map": map[string]interface{}{}}}},
+	} {
+		_, err := sourcemap.Parse(contents)
+		assert.Error(t, err)
+	}
+}
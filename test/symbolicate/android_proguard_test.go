@@ -0,0 +1,57 @@
+package symbolicate_testing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/proguard"
+	"github.com/bugsnag/bugsnag-cli/pkg/symbolicate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mapping = `com.example.Main -> a.a:
+# {"id":"sourceFile","fileName":"Main.kt"}
+    1:3:void helper(int):42:44 -> a
+    1:3:void run():10 -> a
+    void overloaded(int) -> b
+    void other() -> b
+    void nativeMethod() -> c
+com.example.MainException -> a.b:
+`
+
+func TestSymbolicateAndroidProguard(t *testing.T) {
+	t.Log("Testing deobfuscating a Java stack trace")
+
+	parsed, err := proguard.ParseMapping(strings.NewReader(mapping))
+	require.NoError(t, err)
+
+	stackTrace := strings.Join([]string{
+		"a.b: boom",
+		"\tat a.a.a(SourceFile:2)",
+		"\tat a.a.b(Unknown Source)",
+		"\tat a.a.c(Native Method)",
+		"\tat android.os.Handler.dispatchMessage(Handler.java:102)",
+		"Caused by: java.lang.IllegalStateException: a.b",
+		"\t... 3 more",
+	}, "\n")
+
+	var output bytes.Buffer
+	err = symbolicate.SymbolicateAndroidProguard(strings.NewReader(stackTrace), &output, parsed, log.NewLoggerWrapper("debug", log.OutputText))
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"com.example.MainException: boom",
+		"\tat com.example.Main.helper(Main.kt:43)",
+		"\tat com.example.Main.run(Main.kt:10)",
+		"\tat com.example.Main.overloaded(Main.kt)",
+		"\t<OR> at com.example.Main.other(Main.kt)",
+		"\tat com.example.Main.nativeMethod(Native Method)",
+		"\tat android.os.Handler.dispatchMessage(Handler.java:102)",
+		"Caused by: java.lang.IllegalStateException: a.b",
+		"\t... 3 more",
+		"",
+	}, "\n"), output.String())
+}
@@ -0,0 +1,79 @@
+package symbolicate_testing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/symbolicate"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var webpackBundles = []upload.SourceMapBundle{{
+	BundlePath:    "../../features/js/fixtures/js-webpack5/dist/main.js",
+	SourceMapPath: "../../features/js/fixtures/js-webpack5/dist/main.js.map",
+}}
+
+func TestParseJsFrame(t *testing.T) {
+	t.Log("Testing parsing V8, Firefox and Safari stack frames")
+
+	assert.Equal(t, &symbolicate.JsFrame{Function: "Object.foo", File: "https://example.com/main.js", Line: 1, Column: 55},
+		withoutFormat(symbolicate.ParseJsFrame("    at Object.foo (https://example.com/main.js:1:55)")))
+	assert.Equal(t, &symbolicate.JsFrame{File: "/app/main.js", Line: 3, Column: 4},
+		withoutFormat(symbolicate.ParseJsFrame("    at /app/main.js:3:4")))
+	assert.Equal(t, &symbolicate.JsFrame{Function: "foo", File: "https://example.com/main.js", Line: 1, Column: 55},
+		withoutFormat(symbolicate.ParseJsFrame("foo@https://example.com/main.js:1:55")))
+	assert.Equal(t, &symbolicate.JsFrame{File: "https://example.com/main.js", Line: 1, Column: 55},
+		withoutFormat(symbolicate.ParseJsFrame("@https://example.com/main.js:1:55")))
+
+	assert.Nil(t, symbolicate.ParseJsFrame("Error: boom"))
+	assert.Nil(t, symbolicate.ParseJsFrame("    at foo (native)"))
+}
+
+// withoutFormat returns a copy of the frame with only its exported fields.
+func withoutFormat(frame *symbolicate.JsFrame) *symbolicate.JsFrame {
+	if frame == nil {
+		return nil
+	}
+	return &symbolicate.JsFrame{Function: frame.Function, File: frame.File, Line: frame.Line, Column: frame.Column}
+}
+
+func TestSymbolicateJs(t *testing.T) {
+	t.Log("Testing symbolicating a V8 stack trace from a webpack bundle")
+
+	stackTrace := strings.Join([]string{
+		"Error: boom",
+		"    at Object.createElement (https://example.com/vendor.js:1:45)",
+		"    at https://example.com/dist/main.js:1:55",
+		"    at Object.<anonymous> (https://example.com/dist/main.js?v=1:1:114)",
+		"    at Module._compile (node:internal/modules/cjs/loader:1521:14)",
+	}, "\n")
+
+	var output bytes.Buffer
+	err := symbolicate.SymbolicateJs(strings.NewReader(stackTrace), &output, webpackBundles, log.NewLoggerWrapper("debug", log.OutputText))
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"Error: boom",
+		"    at Object.createElement (https://example.com/vendor.js:1:45)",
+		"    at component (webpack://js/./src/index.js:2:28)",
+		"    at Object.<anonymous> (webpack://js/./src/index.js:9:27)",
+		"    at Module._compile (node:internal/modules/cjs/loader:1521:14)",
+		"",
+	}, "\n"), output.String())
+}
+
+func TestSymbolicateJsGecko(t *testing.T) {
+	t.Log("Testing symbolicating a Firefox stack trace keeps its format")
+
+	stackTrace := "@https://example.com/main.js:1:55\n@https://example.com/main.js:1:114\n"
+
+	var output bytes.Buffer
+	err := symbolicate.SymbolicateJs(strings.NewReader(stackTrace), &output, webpackBundles, log.NewLoggerWrapper("debug", log.OutputText))
+
+	require.NoError(t, err)
+	assert.Equal(t, "component@webpack://js/./src/index.js:2:28\n@webpack://js/./src/index.js:9:27\n", output.String())
+}
@@ -0,0 +1,64 @@
+package symbolicate_testing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/symbolicate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var crashLibs = []string{"../testdata/native/stripped/libcrash.so", "../testdata/native/libcrash.so"}
+
+func TestSymbolicateNativeTombstone(t *testing.T) {
+	t.Log("Testing symbolicating a tombstone by matching build IDs")
+
+	stackTrace := strings.Join([]string{
+		"backtrace:",
+		"      #00 pc 0000000000001110  /data/app/lib/x86_64/libcrash.so (crash) (BuildId: 328c36945b4b8a9e16a9ab7d2daceb3b38f565f5)",
+		"      #01 pc 0000000000001125  /data/app/lib/x86_64/libcrash.so (entry+5) (BuildId: 0123456789abcdef)",
+		"      #02 pc 0000000000054321  /apex/com.android.runtime/lib64/bionic/libc.so (abort+164)",
+	}, "\n")
+
+	var output bytes.Buffer
+	err := symbolicate.SymbolicateNative(strings.NewReader(stackTrace), &output, crashLibs, "", log.NewLoggerWrapper("debug", log.OutputText))
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"backtrace:",
+		"      #00 pc 0000000000001110  /data/app/lib/x86_64/libcrash.so (crash) (BuildId: 328c36945b4b8a9e16a9ab7d2daceb3b38f565f5)",
+		"                               trigger",
+		"                               crash.c:7:8",
+		"                               handle",
+		"                               crash.c:11:5",
+		"                               crash",
+		"                               crash.c:15:5",
+		// A different build of the library is not symbolicated
+		"      #01 pc 0000000000001125  /data/app/lib/x86_64/libcrash.so (entry+5) (BuildId: 0123456789abcdef)",
+		"      #02 pc 0000000000054321  /apex/com.android.runtime/lib64/bionic/libc.so (abort+164)",
+		"",
+	}, "\n"), output.String())
+}
+
+func TestSymbolicateNativeAddresses(t *testing.T) {
+	t.Log("Testing symbolicating a glibc backtrace and a list of addresses")
+
+	stackTrace := "/usr/lib/libcrash.so(+0x1116) [0x7f3a20001116]\n0x1125\n"
+
+	var output bytes.Buffer
+	err := symbolicate.SymbolicateNative(strings.NewReader(stackTrace), &output, crashLibs, "libcrash.so", log.NewLoggerWrapper("debug", log.OutputText))
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"/usr/lib/libcrash.so(+0x1116) [0x7f3a20001116]",
+		"    crash",
+		"    crash.c:16:13",
+		"0x1125",
+		"    entry",
+		"    crash.c:20:12",
+		"",
+	}, "\n"), output.String())
+}
@@ -0,0 +1,21 @@
+// Fixture for native symbolication tests, built with:
+//   gcc -O2 -g -shared -fPIC -fdebug-prefix-map=$PWD=. -o libcrash.so crash.c
+//   objcopy --strip-debug libcrash.so stripped/libcrash.so
+#include <stdlib.h>
+
+static inline __attribute__((always_inline)) void trigger(int *p) {
+    *p = 42;
+}
+
+static inline __attribute__((always_inline)) void handle(int *p) {
+    trigger(p);
+}
+
+__attribute__((noinline)) int crash(int *p) {
+    handle(p);
+    return p[1];
+}
+
+int entry(void) {
+    return crash(NULL) + 1;
+}
@@ -99,12 +99,14 @@ func TestProcessAndroidProguard_MissingManifest_Warns(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			AndroidProguard: options.AndroidProguardMapping{
-				Path:          []string{mappingFile},
-				Variant:       "release",
-				ApplicationId: "com.test.app",
-				VersionCode:   "1",
-				VersionName:   "1.0",
+			UploadCommands: options.UploadCommands{
+				AndroidProguard: options.AndroidProguardMapping{
+					Path:          []string{mappingFile},
+					Variant:       "release",
+					ApplicationId: "com.test.app",
+					VersionCode:   "1",
+					VersionName:   "1.0",
+				},
 			},
 		},
 	}
@@ -150,10 +152,12 @@ func TestProcessAndroidProguard_UnreadableManifest_Warns(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			AndroidProguard: options.AndroidProguardMapping{
-				Path:        []string{mappingFile},
-				Variant:     "release",
-				AppManifest: manifestFile,
+			UploadCommands: options.UploadCommands{
+				AndroidProguard: options.AndroidProguardMapping{
+					Path:        []string{mappingFile},
+					Variant:     "release",
+					AppManifest: manifestFile,
+				},
 			},
 		},
 	}
@@ -199,17 +203,19 @@ func TestProcessReactNativeAndroid_MissingManifest_Warns(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			ReactNativeAndroid: options.ReactNativeAndroid{
-				Path:        []string{tempDir},
-				ProjectRoot: tempDir,
-				Android: options.ReactNativeAndroidSpecific{
-					Variant:     "release",
-					VersionCode: "1",
-				},
-				ReactNative: options.ReactNativeShared{
-					Bundle:      bundleFile,
-					SourceMap:   sourcemapFile,
-					VersionName: "1.0",
+			UploadCommands: options.UploadCommands{
+				ReactNativeAndroid: options.ReactNativeAndroid{
+					Path:        []string{tempDir},
+					ProjectRoot: tempDir,
+					Android: options.ReactNativeAndroidSpecific{
+						Variant:     "release",
+						VersionCode: "1",
+					},
+					ReactNative: options.ReactNativeShared{
+						Bundle:      bundleFile,
+						SourceMap:   sourcemapFile,
+						VersionName: "1.0",
+					},
 				},
 			},
 		},
@@ -251,12 +257,14 @@ func TestAndroidManifest_SuccessfulFind_LogsDebug(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			AndroidProguard: options.AndroidProguardMapping{
-				Path:          []string{mappingPath},
-				Variant:       "release",
-				ApplicationId: "com.test.app",
-				VersionCode:   "1",
-				VersionName:   "1.0",
+			UploadCommands: options.UploadCommands{
+				AndroidProguard: options.AndroidProguardMapping{
+					Path:          []string{mappingPath},
+					Variant:       "release",
+					ApplicationId: "com.test.app",
+					VersionCode:   "1",
+					VersionName:   "1.0",
+				},
 			},
 		},
 	}
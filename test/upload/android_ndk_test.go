@@ -33,12 +33,14 @@ func TestProcessAndroidNdk_MissingManifest_Warns(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			AndroidNdk: options.AndroidNdkMapping{
-				Path:          []string{soFile},
-				Variant:       "release",
-				ApplicationId: "com.test.app",
-				VersionCode:   "1",
-				VersionName:   "1.0",
+			UploadCommands: options.UploadCommands{
+				AndroidNdk: options.AndroidNdkMapping{
+					Path:          []string{soFile},
+					Variant:       "release",
+					ApplicationId: "com.test.app",
+					VersionCode:   "1",
+					VersionName:   "1.0",
+				},
 			},
 		},
 	}
@@ -72,12 +74,14 @@ func TestProcessAndroidNdk_WithManifest_NoWarning(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			AndroidNdk: options.AndroidNdkMapping{
-				Path:          []string{fixturePath},
-				Variant:       "release",
-				ApplicationId: "com.test.app",
-				VersionCode:   "1",
-				VersionName:   "1.0",
+			UploadCommands: options.UploadCommands{
+				AndroidNdk: options.AndroidNdkMapping{
+					Path:          []string{fixturePath},
+					Variant:       "release",
+					ApplicationId: "com.test.app",
+					VersionCode:   "1",
+					VersionName:   "1.0",
+				},
 			},
 		},
 	}
@@ -127,13 +131,15 @@ func TestProcessAndroidNdk_ManifestProvidedExplicitly_NoSearch(t *testing.T) {
 			ApiKey: "test-api-key",
 		},
 		Upload: options.Upload{
-			AndroidNdk: options.AndroidNdkMapping{
-				Path:          []string{soFile},
-				AppManifest:   manifestPath,
-				Variant:       "release",
-				ApplicationId: "com.test.app",
-				VersionCode:   "1",
-				VersionName:   "1.0",
+			UploadCommands: options.UploadCommands{
+				AndroidNdk: options.AndroidNdkMapping{
+					Path:          []string{soFile},
+					AppManifest:   manifestPath,
+					Variant:       "release",
+					ApplicationId: "com.test.app",
+					VersionCode:   "1",
+					VersionName:   "1.0",
+				},
 			},
 		},
 	}
@@ -2,9 +2,19 @@ package upload_testing
 
 import (
 	"fmt"
-	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUploadBreakpadSymbolsQueryParams(t *testing.T) {
@@ -24,3 +34,43 @@ func TestUploadBreakpadSymbolsQueryParams(t *testing.T) {
 
 	assert.Equal(t, expectedQueryParams, queryParams)
 }
+
+func TestProcessBreakpadContinuesPastInvalidFiles(t *testing.T) {
+	t.Log("Testing that a symbol file without a MODULE record fails without stopping the other uploads")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.sym"), []byte("FUNC 1000 10 0 main\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "valid.sym"), []byte("MODULE Linux x86_64 ABCDEF0123456789 libvalid.so\n"), 0644))
+
+	var debugFiles []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		debugFiles = append(debugFiles, r.FormValue("debug_file"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	opts := options.CLI{}
+	opts.ApiKey = "1234567890ABCDEF1234567890ABCDEF"
+	opts.Upload.UploadAPIRootUrl = ts.URL
+	opts.Upload.NoCache = true
+	opts.Upload.Breakpad.Path = utils.Paths{dir}
+
+	err := upload.ProcessBreakpad(opts, NewMockLogger())
+	var uploadErrors *server.UploadErrors
+	require.ErrorAs(t, err, &uploadErrors)
+	require.Len(t, uploadErrors.Failures, 1)
+	assert.Equal(t, filepath.Join(dir, "invalid.sym"), uploadErrors.Failures[0].Name)
+	assert.Equal(t, []string{"libvalid.so"}, debugFiles)
+
+	// The MODULE record is not needed when every field read from it is given as an option
+	debugFiles = nil
+	opts.Upload.Breakpad.CpuArch = "x86_64"
+	opts.Upload.Breakpad.CodeFile = "libinvalid.so"
+	opts.Upload.Breakpad.DebugFile = "libinvalid.so"
+	opts.Upload.Breakpad.DebugIdentifier = "0123456789ABCDEF"
+	opts.Upload.Breakpad.Path = utils.Paths{filepath.Join(dir, "invalid.sym")}
+
+	assert.NoError(t, upload.ProcessBreakpad(opts, NewMockLogger()))
+	assert.Equal(t, []string{"libinvalid.so"}, debugFiles)
+}
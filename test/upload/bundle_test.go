@@ -0,0 +1,20 @@
+package upload_testing
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessUploadBundleRequiresApiKey(t *testing.T) {
+	t.Log("Testing that uploading a symbol bundle fails clearly when no API key is given")
+
+	opts := options.CLI{}
+	opts.Upload.Bundle.Archive = filepath.Join(t.TempDir(), "symbols.zip")
+
+	err := upload.ProcessUploadBundle(opts, NewMockLogger())
+	assert.ErrorContains(t, err, "missing api key")
+}
@@ -31,9 +31,9 @@ func TestGetArchFromElfFile(t *testing.T) {
 	assert.Equal(t, results, "arm64", "Arch should match")
 }
 
-func TestDwarfDumpUuid(t *testing.T) {
-	t.Log("Testing getting a build ID from a Dwarf file")
-	results, err := upload.DwarfDumpUuid("../../features/dart/fixtures/app-debug-info/app.ios-arm64.symbols", "../../features/dart/fixtures/build/ios/iphoneos/Runner.app/Frameworks/App.framework/App", "arm64")
+func TestMachoUuid(t *testing.T) {
+	t.Log("Testing getting a build ID from a Mach-O file")
+	results, err := upload.MachoUuid("../../features/dart/fixtures/app-debug-info/app.ios-arm64.symbols", "../../features/dart/fixtures/build/ios/iphoneos/Runner.app/Frameworks/App.framework/App", "arm64")
 
 	if err != nil {
 		t.Error(err)
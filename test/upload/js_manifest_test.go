@@ -0,0 +1,136 @@
+package upload_testing
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifestFixtures = "../testdata/js-manifests"
+
+func TestReadWebpackStats(t *testing.T) {
+	t.Log("Testing pairing bundles with source maps from a webpack stats.json file")
+
+	dist := filepath.Join(manifestFixtures, "webpack", "dist")
+	manifestPath := upload.FindBundlerManifest(dist)
+	require.Equal(t, filepath.Join(dist, "stats.json"), manifestPath)
+
+	manifest, err := upload.ReadBundlerManifest(manifestPath, dist, "")
+	require.NoError(t, err)
+	assert.Equal(t, "webpack", manifest.Bundler)
+	assert.Equal(t, "https://cdn.example.com/static/", manifest.PublicUrl())
+	// CSS source maps are not uploaded
+	assert.Equal(t, []upload.SourceMapBundle{
+		{BundlePath: filepath.Join(dist, "342.4d5e6f.js"), SourceMapPath: filepath.Join(dist, "342.4d5e6f.js.map"), UrlPath: "342.4d5e6f.js"},
+		{BundlePath: filepath.Join(dist, "main.1a2b3c.js"), SourceMapPath: filepath.Join(dist, "main.1a2b3c.js.map"), UrlPath: "main.1a2b3c.js"},
+	}, manifest.Bundles)
+}
+
+func TestReadViteManifest(t *testing.T) {
+	t.Log("Testing pairing bundles with source maps from a Vite manifest")
+
+	dist := filepath.Join(manifestFixtures, "vite", "dist")
+	// The web app manifest.json is not mistaken for a bundler manifest
+	manifestPath := upload.FindBundlerManifest(dist)
+	require.Equal(t, filepath.Join(dist, ".vite", "manifest.json"), manifestPath)
+	_, err := upload.ReadBundlerManifest(filepath.Join(dist, "manifest.json"), dist, "")
+	assert.Error(t, err)
+
+	manifest, err := upload.ReadBundlerManifest(manifestPath, dist, "")
+	require.NoError(t, err)
+	assert.Equal(t, "vite", manifest.Bundler)
+	assert.Empty(t, manifest.PublicUrl())
+	assert.Equal(t, []upload.SourceMapBundle{
+		{BundlePath: filepath.Join(dist, "assets", "index-BxK2a1.js"), SourceMapPath: filepath.Join(dist, "assets", "index-BxK2a1.js.map"), UrlPath: "assets/index-BxK2a1.js"},
+		{BundlePath: filepath.Join(dist, "assets", "lazy-D3f9Qz.js"), SourceMapPath: filepath.Join(dist, "assets", "lazy-D3f9Qz.js.map"), UrlPath: "assets/lazy-D3f9Qz.js"},
+	}, manifest.Bundles)
+}
+
+func TestReadEsbuildMetafile(t *testing.T) {
+	t.Log("Testing pairing bundles with source maps from an esbuild metafile")
+
+	dist := filepath.Join(manifestFixtures, "esbuild", "dist")
+	manifest, err := upload.ReadBundlerManifest(filepath.Join(manifestFixtures, "esbuild", "meta.json"), dist, "")
+	require.NoError(t, err)
+	assert.Equal(t, "esbuild", manifest.Bundler)
+	// Outputs without a source map are skipped
+	assert.Equal(t, []upload.SourceMapBundle{
+		{BundlePath: filepath.Join(dist, "out.js"), SourceMapPath: filepath.Join(dist, "out.js.map"), UrlPath: "out.js"},
+	}, manifest.Bundles)
+
+	assert.Empty(t, upload.FindBundlerManifest(dist))
+}
+
+func TestReadWebpackStatsRelativePublicPath(t *testing.T) {
+	t.Log("Testing that a relative webpack publicPath is added to the URL path of each bundle")
+
+	dist := filepath.Join(manifestFixtures, "webpack-relative", "dist")
+	manifest, err := upload.ReadBundlerManifest(filepath.Join(dist, "stats.json"), dist, "")
+	require.NoError(t, err)
+	assert.Empty(t, manifest.PublicUrl())
+	assert.Equal(t, "main.5e6f7a.js", manifest.Bundles[0].UrlPath)
+
+	manifest.JoinPublicPath()
+	assert.Equal(t, []upload.SourceMapBundle{
+		{BundlePath: filepath.Join(dist, "main.5e6f7a.js"), SourceMapPath: filepath.Join(dist, "main.5e6f7a.js.map"), UrlPath: "static/js/main.5e6f7a.js"},
+	}, manifest.Bundles)
+}
+
+func TestReadViteManifestBase(t *testing.T) {
+	t.Log("Testing that a non-root Vite base given as the public path is added to the URL path of each bundle")
+
+	dist := filepath.Join(manifestFixtures, "vite", "dist")
+	manifestPath := filepath.Join(dist, ".vite", "manifest.json")
+
+	manifest, err := upload.ReadBundlerManifest(manifestPath, dist, "/app/")
+	require.NoError(t, err)
+	assert.Equal(t, "/app/", manifest.PublicPath)
+	manifest.JoinPublicPath()
+	assert.Equal(t, "app/assets/index-BxK2a1.js", manifest.Bundles[0].UrlPath)
+	assert.Equal(t, "app/assets/lazy-D3f9Qz.js", manifest.Bundles[1].UrlPath)
+
+	manifest, err = upload.ReadBundlerManifest(manifestPath, dist, "./nested/app")
+	require.NoError(t, err)
+	manifest.JoinPublicPath()
+	assert.Equal(t, "nested/app/assets/index-BxK2a1.js", manifest.Bundles[0].UrlPath)
+
+	// Absolute URLs are used as the base URL instead
+	manifest, err = upload.ReadBundlerManifest(manifestPath, dist, "https://cdn.example.com/app/")
+	require.NoError(t, err)
+	manifest.JoinPublicPath()
+	assert.Equal(t, "https://cdn.example.com/app/", manifest.PublicUrl())
+	assert.Equal(t, "assets/index-BxK2a1.js", manifest.Bundles[0].UrlPath)
+}
+
+func TestProcessJsPublicPathWithBaseUrl(t *testing.T) {
+	t.Log("Testing that a relative public path from a manifest found automatically is not added to --base-url")
+
+	dist := filepath.Join(manifestFixtures, "webpack-relative", "dist")
+	bundleUrl := func(baseUrl string, publicPath string) string {
+		opts := options.CLI{}
+		opts.ApiKey = "1234567890ABCDEF1234567890ABCDEF"
+		opts.DryRun = true
+		opts.Upload.Js.Path = utils.Paths{dist}
+		opts.Upload.Js.VersionName = "1.0.0"
+		opts.Upload.Js.BaseUrl = baseUrl
+		opts.Upload.Js.PublicPath = publicPath
+
+		logger := NewMockLogger()
+		require.NoError(t, upload.ProcessJs(opts, logger))
+		for _, msg := range logger.DebugMessages {
+			if url, _, found := strings.Cut(strings.TrimPrefix(msg, "Generated URL "), " using the base URL"); found {
+				return url
+			}
+		}
+		return ""
+	}
+
+	assert.Equal(t, "https://cdn.example.com/static/js/main.5e6f7a.js", bundleUrl("https://cdn.example.com/static/js/", ""))
+	assert.Equal(t, "https://cdn.example.com/static/js/main.5e6f7a.js", bundleUrl("https://cdn.example.com/", "/static/js/"))
+}
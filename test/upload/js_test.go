@@ -12,7 +12,7 @@ import (
 
 func TestPopulateSourceMap(t *testing.T) {
 	t.Log("Testing populating source map")
-	logger := log.NewLoggerWrapper("debug")
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
 
 	sourceMapPath := "../testdata/js-nosources/dist/main.js.map"
 	results, err := upload.ReadSourceMap(sourceMapPath, logger)
@@ -38,7 +38,7 @@ func TestPopulateSourceMap(t *testing.T) {
 }
 
 func TestResolveSourceMapPaths_IgnoresNodeModulesAndCssMaps(t *testing.T) {
-	logger := log.NewLoggerWrapper("debug")
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
 
 	// Create a temporary directory structure
 	tempDir := t.TempDir()
@@ -96,7 +96,7 @@ func TestResolveSourceMapPaths_IgnoresNodeModulesAndCssMaps(t *testing.T) {
 }
 
 func TestExtractSourceMappingURL(t *testing.T) {
-	logger := log.NewLoggerWrapper("debug")
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
 	tempDir := t.TempDir()
 
 	t.Run("Modern syntax with //#", func(t *testing.T) {
@@ -283,7 +283,7 @@ func TestExtractSourceMappingURL(t *testing.T) {
 }
 
 func TestResolveBundlePaths(t *testing.T) {
-	logger := log.NewLoggerWrapper("debug")
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
 	tempDir := t.TempDir()
 
 	// Create various bundle files
@@ -377,7 +377,7 @@ func TestResolveBundlePaths(t *testing.T) {
 //
 // This test verifies the fallback .map suffix matching works correctly.
 func TestResolveSourceMapPaths_TIER3B_ViteHiddenSourcemaps(t *testing.T) {
-	logger := log.NewLoggerWrapper("debug")
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
 
 	t.Run("Finds source map by .map suffix when sourceMappingURL missing (Vite hidden mode)", func(t *testing.T) {
 		tempDir := t.TempDir()
@@ -534,3 +534,30 @@ func TestResolveSourceMapPaths_TIER3B_ViteHiddenSourcemaps(t *testing.T) {
 		}
 	})
 }
+
+func TestResolveSourceMapArgument(t *testing.T) {
+	t.Log("Testing that a .map path is treated as the source map itself")
+
+	tmpDir := t.TempDir()
+	mapPath := filepath.Join(tmpDir, "main.js.map")
+	if err := os.WriteFile(mapPath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceMapPath, outputPath := upload.ResolveSourceMapArgument(mapPath, "")
+	if sourceMapPath != mapPath || outputPath != tmpDir {
+		t.Errorf("expected %s in %s, got %s in %s", mapPath, tmpDir, sourceMapPath, outputPath)
+	}
+
+	// A source map given with --source-map is used for any path
+	sourceMapPath, outputPath = upload.ResolveSourceMapArgument(mapPath, "other.js.map")
+	if sourceMapPath != "other.js.map" || outputPath != mapPath {
+		t.Errorf("expected other.js.map in %s, got %s in %s", mapPath, sourceMapPath, outputPath)
+	}
+
+	// Directories are searched for source maps
+	sourceMapPath, outputPath = upload.ResolveSourceMapArgument(tmpDir, "")
+	if sourceMapPath != "" || outputPath != tmpDir {
+		t.Errorf("expected a search of %s, got %q in %s", tmpDir, sourceMapPath, outputPath)
+	}
+}
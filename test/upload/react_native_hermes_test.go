@@ -0,0 +1,87 @@
+package upload_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsHermesBundle(t *testing.T) {
+	t.Log("Testing detecting Hermes bytecode bundles by their magic number")
+
+	dir := t.TempDir()
+	hermesBundle := filepath.Join(dir, "index.android.bundle")
+	require.NoError(t, os.WriteFile(hermesBundle, []byte{0xc6, 0x1f, 0xbc, 0x03, 0xc1, 0x03, 0x19, 0x1f, 0x60, 0x00}, 0644))
+	jsBundle := filepath.Join(dir, "main.jsbundle")
+	require.NoError(t, os.WriteFile(jsBundle, []byte("var __BUNDLE_START_TIME__=this.nativePerformanceNow"), 0644))
+
+	assert.True(t, upload.IsHermesBundle(hermesBundle))
+	assert.False(t, upload.IsHermesBundle(jsBundle))
+	assert.False(t, upload.IsHermesBundle(filepath.Join(dir, "missing.bundle")))
+}
+
+func TestFindHermesSourceMaps(t *testing.T) {
+	t.Log("Testing finding the packager and compiler source maps of a Hermes bundle")
+
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "assets", "index.android.bundle")
+	sourceMapPath := filepath.Join(dir, "generated", "index.android.bundle.map")
+	intermediatesPath := filepath.Join(dir, "intermediates")
+	for _, path := range []string{
+		bundlePath,
+		sourceMapPath,
+		filepath.Join(intermediatesPath, "index.android.bundle.packager.map"),
+		filepath.Join(intermediatesPath, "index.android.bundle.compiler.map"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	}
+
+	packagerMap, compilerMap := upload.FindHermesSourceMaps(bundlePath, sourceMapPath, []string{intermediatesPath})
+	assert.Equal(t, filepath.Join(intermediatesPath, "index.android.bundle.packager.map"), packagerMap)
+	assert.Equal(t, filepath.Join(intermediatesPath, "index.android.bundle.compiler.map"), compilerMap)
+
+	// Source maps next to the source map are found without any other directories
+	packagerMap, compilerMap = upload.FindHermesSourceMaps(bundlePath, sourceMapPath, nil)
+	assert.Empty(t, packagerMap)
+	assert.Empty(t, compilerMap)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "generated", "index.android.bundle.packager.map"), []byte("{}"), 0644))
+	packagerMap, _ = upload.FindHermesSourceMaps(bundlePath, sourceMapPath, nil)
+	assert.Equal(t, filepath.Join(dir, "generated", "index.android.bundle.packager.map"), packagerMap)
+}
+
+func TestFindXcodeHermesSourceMaps(t *testing.T) {
+	t.Log("Testing finding the Hermes source maps named main.jsbundle.map by react-native-xcode.sh")
+
+	dir := t.TempDir()
+	configurationBuildDir := filepath.Join(dir, "Build", "Products", "Release-iphoneos")
+	bundlePath := filepath.Join(configurationBuildDir, "MyApp.app", "main.jsbundle")
+	sourceMapPath := filepath.Join(dir, "sourcemaps", "main.jsbundle.map")
+	for _, path := range []string{
+		bundlePath,
+		bundlePath + ".map",
+		sourceMapPath,
+		filepath.Join(configurationBuildDir, "main.jsbundle.map"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	}
+
+	packagerMap, compilerMap := upload.FindXcodeHermesSourceMaps(bundlePath, sourceMapPath, configurationBuildDir)
+	assert.Equal(t, filepath.Join(configurationBuildDir, "main.jsbundle.map"), packagerMap)
+	assert.Equal(t, bundlePath+".map", compilerMap)
+
+	// The final source map written over the packager map is not composed again
+	packagerMap, compilerMap = upload.FindXcodeHermesSourceMaps(bundlePath, filepath.Join(configurationBuildDir, "main.jsbundle.map"), configurationBuildDir)
+	assert.Empty(t, packagerMap)
+	assert.Empty(t, compilerMap)
+
+	// The build products directory is needed to find the packager map
+	packagerMap, compilerMap = upload.FindXcodeHermesSourceMaps(bundlePath, sourceMapPath, "")
+	assert.Empty(t, packagerMap)
+	assert.Empty(t, compilerMap)
+}
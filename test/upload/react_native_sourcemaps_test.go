@@ -0,0 +1,41 @@
+package upload_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessReactNativeSourcemapsFlattenError(t *testing.T) {
+	t.Log("Testing that a source map that cannot be flattened fails the upload when flattening is requested")
+
+	dir := t.TempDir()
+	sourceMapPath := filepath.Join(dir, "index.android.bundle.map")
+	bundlePath := filepath.Join(dir, "index.android.bundle")
+	require.NoError(t, os.WriteFile(sourceMapPath, []byte(`{"version": 3, "sections": [{"offset": {"line": 0, "column": 0}, "map": {"version": 3, "sources": ["a.js"], "names": [], "mappings": "!"}}]}`), 0644))
+	require.NoError(t, os.WriteFile(bundlePath, []byte("a()"), 0644))
+
+	opts := options.CLI{}
+	opts.ApiKey = "1234567890ABCDEF1234567890ABCDEF"
+	opts.DryRun = true
+	opts.Upload.ReactNativeSourcemaps = options.ReactNativeSourcemaps{
+		VersionName:      "1.0",
+		Platform:         "android",
+		SourceMap:        sourceMapPath,
+		Bundle:           bundlePath,
+		FlattenIndexMaps: true,
+	}
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
+
+	assert.ErrorContains(t, upload.ProcessReactNativeSourcemaps(opts, logger), "cannot flatten index map")
+
+	// The source map is uploaded as it is if flattening was not requested
+	opts.Upload.ReactNativeSourcemaps.FlattenIndexMaps = false
+	assert.NoError(t, upload.ProcessReactNativeSourcemaps(opts, logger))
+}
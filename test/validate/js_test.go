@@ -0,0 +1,147 @@
+package validate_testing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"github.com/bugsnag/bugsnag-cli/pkg/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bundle = "console.log(1);console.log(2);\n//# sourceMappingURL=app.js.map\n"
+
+// writeProject writes a bundle, its source and the given source map to a temporary directory.
+func writeProject(t *testing.T, sourceMap map[string]interface{}) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "index.js"), []byte("console.log(1);\nconsole.log(2);\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte(bundle), 0644))
+
+	data, err := json.Marshal(sourceMap)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js.map"), data, 0644))
+	return dir
+}
+
+func validSourceMap() map[string]interface{} {
+	return map[string]interface{}{
+		"version":  3,
+		"file":     "app.js",
+		"sources":  []string{"src/index.js"},
+		"names":    []string{"console"},
+		"mappings": "AAAAA,eACA",
+	}
+}
+
+func validateProject(t *testing.T, dir string) validate.SourceMapResult {
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
+	return validate.ValidateSourceMap(filepath.Join(dir, "app.js.map"), filepath.Join(dir, "app.js"), 0, logger)
+}
+
+func TestValidateSourceMap(t *testing.T) {
+	t.Log("Testing that a valid source map has no problems")
+
+	result := validateProject(t, writeProject(t, validSourceMap()))
+
+	assert.True(t, result.Valid())
+	assert.Empty(t, result.Errors)
+	assert.Empty(t, result.Warnings)
+}
+
+func TestValidateSourceMapFileMismatch(t *testing.T) {
+	t.Log("Testing that a file field that does not match the bundle is reported")
+
+	sourceMap := validSourceMap()
+	sourceMap["file"] = "other.js"
+	result := validateProject(t, writeProject(t, sourceMap))
+
+	assert.False(t, result.Valid())
+	assert.Contains(t, result.Errors[0], `the file field "other.js" does not match the bundle`)
+}
+
+func TestValidateSourceMapMissingSource(t *testing.T) {
+	t.Log("Testing that sources that cannot be found are reported unless they have sourcesContent")
+
+	sourceMap := validSourceMap()
+	sourceMap["sources"] = []string{"src/missing.js"}
+	result := validateProject(t, writeProject(t, sourceMap))
+
+	assert.False(t, result.Valid())
+	assert.Contains(t, result.Errors[0], `source "src/missing.js" cannot be found`)
+
+	sourceMap["sourcesContent"] = []string{"console.log(1);\nconsole.log(2);\n"}
+	result = validateProject(t, writeProject(t, sourceMap))
+
+	assert.True(t, result.Valid(), result.Errors)
+}
+
+func TestValidateSourceMapInvalidMappings(t *testing.T) {
+	t.Log("Testing that mappings that cannot be decoded are reported")
+
+	sourceMap := validSourceMap()
+	sourceMap["mappings"] = "AAAA,!"
+	result := validateProject(t, writeProject(t, sourceMap))
+
+	assert.False(t, result.Valid())
+	assert.Contains(t, result.Errors[0], "invalid mappings")
+}
+
+func TestValidateSourceMapOutOfRange(t *testing.T) {
+	t.Log("Testing that mappings outside of the bundle, sources and names are reported")
+
+	sourceMap := validSourceMap()
+	// A column past the end of the first line, then mappings on lines 7-9 that the bundle does
+	// not have, the second of which is to line 11 of a two-line source, and the third of which
+	// refers to a second source and name
+	sourceMap["mappings"] = "AAAAA,+BAAA;;;;;;AAAA;AAUA;ACAAC"
+	result := validateProject(t, writeProject(t, sourceMap))
+
+	assert.Equal(t, []string{
+		"1 mappings refer to a source that is not in the sources list, first at generated line 9, column 0",
+		"1 mappings refer to a name that is not in the names list, first at generated line 9, column 0",
+		"4 of 5 sampled mappings are outside of the bundle, first at generated line 1, column 31",
+		"1 of 5 sampled mappings are outside of their original source, first at generated line 8, column 0",
+	}, result.Errors)
+}
+
+func TestValidateIndexMap(t *testing.T) {
+	t.Log("Testing that each section of an index map is checked using its offset")
+
+	section := validSourceMap()
+	delete(section, "file")
+	indexMap := map[string]interface{}{
+		"version": 3,
+		"file":    "app.js",
+		"sections": []map[string]interface{}{
+			{"offset": map[string]int{"line": 0, "column": 0}, "map": section},
+			{"offset": map[string]int{"line": 0, "column": 20}, "map": section},
+		},
+	}
+	result := validateProject(t, writeProject(t, indexMap))
+
+	assert.Equal(t, []string{
+		"section 1: 1 of 2 sampled mappings are outside of the bundle, first at generated line 1, column 35",
+	}, result.Errors)
+}
+
+func TestProcessValidateJs(t *testing.T) {
+	t.Log("Testing that validating a directory fails only if a source map has problems")
+
+	logger := log.NewLoggerWrapper("debug", log.OutputText)
+	var globalOptions options.CLI
+	globalOptions.Validate.Js.Path = utils.Paths{writeProject(t, validSourceMap())}
+
+	assert.NoError(t, validate.ProcessValidateJs(globalOptions, logger))
+
+	sourceMap := validSourceMap()
+	sourceMap["file"] = "other.js"
+	globalOptions.Validate.Js.Path = utils.Paths{writeProject(t, sourceMap)}
+
+	assert.EqualError(t, validate.ProcessValidateJs(globalOptions, logger), "1 of 1 source maps have problems")
+}